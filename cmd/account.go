@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Manage accounts (checking, savings, cash, ...)",
+}
+
+var accountAddCmd = &cobra.Command{
+	Use:     "add <name>",
+	Short:   "Add an account",
+	Example: `acc account add savings`,
+	Args:    cobra.ExactArgs(1),
+	Run:     AccountAdd,
+}
+
+var accountLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List accounts with their running balance per currency",
+	Run:   AccountLs,
+}
+
+var accountRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove an account without transactions",
+	Args:  cobra.ExactArgs(1),
+	Run:   AccountRm,
+}
+
+func init() {
+	RootCmd.AddCommand(accountCmd)
+	accountCmd.AddCommand(accountAddCmd, accountLsCmd, accountRmCmd)
+}
+
+func AccountAdd(cmd *cobra.Command, args []string) {
+	err := database.NewAccountRepository().CreateAccount(args[0])
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func AccountRm(cmd *cobra.Command, args []string) {
+	err := database.NewAccountRepository().DeleteAccount(args[0])
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func AccountLs(cmd *cobra.Command, args []string) {
+	balances, err := database.NewAccountRepository().GetAccountBalances()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Account", "Currency", "Income", "Expense", "Transfer In", "Transfer Out", "Balance"})
+	for _, b := range balances {
+		t.AppendRow(table.Row{
+			b.Name,
+			b.Currency,
+			b.Income.String(),
+			b.Expense.String(),
+			b.TransferIn.String(),
+			b.TransferOut.String(),
+			b.Balance.String(),
+		})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
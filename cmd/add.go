@@ -1,25 +1,38 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
+	"github.com/elliot40404/acc/cmd/list"
 	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 var addCmd = &cobra.Command{
-	Use:     "add",
-	Short:   "Add a new income or expense",
-	Example: `acc add -t income -d "Paycheck" -a 1000`,
-	Run:     Add,
+	Use:   "add",
+	Short: "Add a new income, expense or transfer",
+	Example: `acc add -t income -d "Paycheck" -a 1000 -D yesterday
+acc add -t transfer -d "Savings" -a 200 --account checking --to savings`,
+	Run: Add,
 }
 
 func init() {
 	RootCmd.AddCommand(addCmd)
-	addCmd.Flags().StringP("type", "t", "", "Type of transaction (income or expense)")
+	addCmd.Flags().StringP("type", "t", "", "Type of transaction (income, expense or transfer)")
 	addCmd.Flags().StringP("description", "d", "", "Description")
-	addCmd.Flags().Float64P("amount", "a", 0, "Amount")
+	addCmd.Flags().String("note", "", "Longer note, searchable with acc search")
+	addCmd.Flags().StringP("amount", "a", "", "Amount (e.g. 12.34)")
+	addCmd.Flags().String("currency", "", "Currency of the amount, e.g. EUR (default: base currency)")
+	addCmd.Flags().String("account", "", "Account the transaction is booked on (default: default)")
+	addCmd.Flags().String("to", "", "Destination account of a transfer")
+	addCmd.Flags().StringP("category", "c", "", "Category path (e.g. \"Food > Groceries\")")
+	addCmd.Flags().StringSlice("tag", []string{}, "Tag the transaction, can be repeated (e.g. --tag reimbursable --tag trip-berlin)")
+	addCmd.Flags().StringP("date", "D", "", "Date of the transaction (e.g. 2024-01-31, yesterday) (default: now)")
 	addCmd.MarkFlagRequired("type")
 	addCmd.MarkFlagRequired("description")
 	addCmd.MarkFlagRequired("amount")
@@ -28,20 +41,106 @@ func init() {
 func Add(cmd *cobra.Command, args []string) {
 	transactionType, _ := cmd.Flags().GetString("type")
 	description, _ := cmd.Flags().GetString("description")
-	amount, _ := cmd.Flags().GetFloat64("amount")
-	if transactionType != "income" && transactionType != "expense" {
+	note, _ := cmd.Flags().GetString("note")
+	rawAmount, _ := cmd.Flags().GetString("amount")
+	amount, err := money.Parse(rawAmount)
+	if err != nil {
+		fmt.Println(utils.AmountSyntaxError)
+		return
+	}
+	if transactionType != "income" && transactionType != "expense" && transactionType != "transfer" {
 		slog.Error("Invalid transaction type", "Type", transactionType)
-		fmt.Println("Supported transaction types: income, expense")
+		fmt.Println("Supported transaction types: income, expense, transfer")
+		return
+	}
+	accountID, toAccountID, err := resolveAccounts(cmd, transactionType)
+	if err != nil {
+		fmt.Println(err)
 		return
 	}
-	fmt.Printf("Adding %s transaction: %s for $%.2f\n", transactionType, description, amount)
+	var occurredAt string
+	if date, _ := cmd.Flags().GetString("date"); date != "" {
+		t, err := utils.ParseDate(date)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		occurredAt = t.Format(utils.DBTimeFormat)
+	}
+	var categoryID *int
+	if category, _ := cmd.Flags().GetString("category"); category != "" {
+		id, err := database.NewCategoryRepository().FindCategory(category)
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("Create it first with: acc category add \"" + category + "\"")
+			return
+		}
+		categoryID = &id
+	}
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	if err := list.ValidateTags(tags, false); err != nil {
+		fmt.Println(err)
+		return
+	}
+	currency, _ := cmd.Flags().GetString("currency")
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency, err = database.NewFxRepository().GetBaseCurrency()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if err := list.ValidateCurrency(currency); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Adding %s transaction: %s for %s %s\n", transactionType, description, amount, currency)
 	db := database.NewTransactionRepository()
-	err := db.CreateTransaction(database.Transaction{
+	_, err = db.CreateTransaction(database.Transaction{
 		Type:        transactionType,
 		Description: description,
+		Note:        note,
 		Amount:      amount,
+		OccurredAt:  occurredAt,
+		CategoryID:  categoryID,
+		Tags:        tags,
+		AccountID:   accountID,
+		ToAccountID: toAccountID,
+		Currency:    currency,
 	})
 	if err != nil {
 		slog.Error("Failed to create transaction", "Error", err.Error())
 	}
 }
+
+// resolveAccounts looks up --account and --to, a destination is required for transfers only
+func resolveAccounts(cmd *cobra.Command, transactionType string) (int, *int, error) {
+	accounts := database.NewAccountRepository()
+	accountID := database.DefaultAccountID
+	if account, _ := cmd.Flags().GetString("account"); account != "" {
+		id, err := accounts.FindAccount(account)
+		if err != nil {
+			return 0, nil, err
+		}
+		accountID = id
+	}
+	to, _ := cmd.Flags().GetString("to")
+	if transactionType != "transfer" {
+		if to != "" {
+			return 0, nil, errors.New("--to can only be used with transfers")
+		}
+		return accountID, nil, nil
+	}
+	if to == "" {
+		return 0, nil, errors.New("transfers need a destination account, use --to")
+	}
+	toAccountID, err := accounts.FindAccount(to)
+	if err != nil {
+		return 0, nil, err
+	}
+	if toAccountID == accountID {
+		return 0, nil, errors.New("cannot transfer to the same account")
+	}
+	return accountID, &toAccountID, nil
+}
@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/backup"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup [path]",
+	Short: "Back up the database to path or to the backups directory",
+	Long: `Back up the database to path or, without a path, to the backups directory. The backup is
+consistent even while another acc process is writing to the database.
+
+acc also saves a backup to the backups directory before acc rm --all, imports, emptying the
+trash, migrations and restores. Backups in the backups directory are pruned with the
+retention policy, see acc backup policy. The backups directory of a ledger is next to its
+database, the one of a database picked with --db or $ACC_DB is in the app directory.`,
+	Example: `acc backup
+acc backup ~/Dropbox/acc.db`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  Backup,
+}
+
+var backupLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the backups in the backups directory",
+	Args:  cobra.NoArgs,
+	Run:   BackupLs,
+}
+
+var backupPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the backups the retention policy does not keep",
+	Args:  cobra.NoArgs,
+	Run:   BackupPrune,
+}
+
+var backupPolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Show or change which backups are kept in the backups directory",
+	Long: `Show or change which backups are kept in the backups directory: the last --keep-last
+backups, the newest backup of each of the last --keep-daily days and the newest backup of
+each of the last --keep-monthly months.`,
+	Example: `acc backup policy
+acc backup policy --keep-last 10 --keep-monthly 24`,
+	Args: cobra.NoArgs,
+	Run:  BackupPolicy,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Replace the database with a backup",
+	Long: `Replace the database with a backup, given as a path or as the name of a file in the
+backups directory. The backup is checked for damage first and the current database is backed
+up before it is replaced.`,
+	Example: `acc restore ~/Dropbox/acc.db
+acc restore acc-manual-20260102-150405.db`,
+	Args: cobra.ExactArgs(1),
+	Run:  Restore,
+}
+
+func init() {
+	RootCmd.AddCommand(backupCmd, restoreCmd)
+	backupCmd.AddCommand(backupLsCmd, backupPruneCmd, backupPolicyCmd)
+	backupPolicyCmd.Flags().Int("keep-last", backup.DefaultPolicy.KeepLast, "number of latest backups to keep")
+	backupPolicyCmd.Flags().Int("keep-daily", backup.DefaultPolicy.KeepDaily, "number of days to keep the newest backup of")
+	backupPolicyCmd.Flags().Int("keep-monthly", backup.DefaultPolicy.KeepMonthly, "number of months to keep the newest backup of")
+}
+
+func Backup(cmd *cobra.Command, args []string) {
+	dry := cmd.Flag("dry").Value.String() == "true"
+	if len(args) == 0 {
+		if dry {
+			fmt.Println("Would back up the database to", backup.Dir())
+			return
+		}
+		path, err := autoBackup("manual")
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println("Backup saved to", path)
+		return
+	}
+	path, err := filepath.Abs(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if dry {
+		fmt.Println("Would back up the database to", path)
+		return
+	}
+	if err := database.Backup(path); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Backup saved to", path)
+}
+
+func BackupLs(cmd *cobra.Command, args []string) {
+	dir := backup.Dir()
+	files, err := backup.List(dir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.SetTitle("Backups in " + dir)
+	t.AppendHeader(table.Row{"File", "Reason", "Time", "Size"})
+	for _, file := range files {
+		t.AppendRow(table.Row{filepath.Base(file.Path), file.Label, utils.HRTime(file.Time.Format(time.RFC3339)), formatSize(file.Size)})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
+
+func BackupPrune(cmd *cobra.Command, args []string) {
+	dry := cmd.Flag("dry").Value.String() == "true"
+	removed, err := pruneBackups(dry)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	verb := "Removed"
+	if dry {
+		verb = "Would remove"
+	}
+	for _, file := range removed {
+		fmt.Println(verb, filepath.Base(file.Path))
+	}
+	if len(removed) == 0 {
+		fmt.Println("No backups to remove")
+	}
+}
+
+func BackupPolicy(cmd *cobra.Command, args []string) {
+	dir := backup.Dir()
+	policy, err := backup.LoadPolicy(dir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	changed := cmd.Flags().Changed("keep-last") || cmd.Flags().Changed("keep-daily") || cmd.Flags().Changed("keep-monthly")
+	if changed {
+		if cmd.Flags().Changed("keep-last") {
+			policy.KeepLast, _ = cmd.Flags().GetInt("keep-last")
+		}
+		if cmd.Flags().Changed("keep-daily") {
+			policy.KeepDaily, _ = cmd.Flags().GetInt("keep-daily")
+		}
+		if cmd.Flags().Changed("keep-monthly") {
+			policy.KeepMonthly, _ = cmd.Flags().GetInt("keep-monthly")
+		}
+		if err := policy.Validate(); err != nil {
+			fmt.Println(err)
+			return
+		}
+		if cmd.Flag("dry").Value.String() == "true" {
+			fmt.Printf("Would keep the last %d backups, daily backups for %d days and monthly backups for %d months\n", policy.KeepLast, policy.KeepDaily, policy.KeepMonthly)
+			return
+		}
+		if err := backup.SavePolicy(dir, policy); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	fmt.Printf("Keeping the last %d backups, daily backups for %d days and monthly backups for %d months\n", policy.KeepLast, policy.KeepDaily, policy.KeepMonthly)
+}
+
+func Restore(cmd *cobra.Command, args []string) {
+	path := args[0]
+	// a bare file name refers to the backups directory unless it is in the working directory
+	if _, err := os.Stat(path); err != nil && filepath.Base(path) == path {
+		path = filepath.Join(backup.Dir(), path)
+	}
+	version, err := database.VerifyDatabase(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Printf("Would restore %s (schema version %d)\n", path, version)
+		return
+	}
+	fmt.Printf("Replacing the database with %s. ", path)
+	if !utils.PromptConfirmation() {
+		fmt.Println("Aborted")
+		return
+	}
+	saved, err := autoBackup("restore")
+	if err != nil {
+		fmt.Println("failed to back up the database, nothing was restored:", err)
+		return
+	}
+	fmt.Println("Backup of the replaced database saved to", saved)
+	if err := database.Restore(path); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Database restored from", path)
+}
+
+// autoBackup backs the database up to the backups directory, label says why, and prunes
+// the backups directory
+func autoBackup(label string) (string, error) {
+	dir := backup.Dir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := backup.NewPath(dir, label, time.Now())
+	if err := database.Backup(path); err != nil {
+		return "", err
+	}
+	// the backup is saved, failing to prune is only worth a notice
+	if _, err := pruneBackups(false); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to prune backups:", err)
+	}
+	return path, nil
+}
+
+// pruneBackups removes the backups the retention policy does not keep and returns them
+func pruneBackups(dry bool) ([]backup.File, error) {
+	dir := backup.Dir()
+	policy, err := backup.LoadPolicy(dir)
+	if err != nil {
+		return nil, err
+	}
+	files, err := backup.List(dir)
+	if err != nil {
+		return nil, err
+	}
+	_, remove := backup.Prune(files, policy, time.Now())
+	if dry {
+		return remove, nil
+	}
+	for i, file := range remove {
+		if err := os.Remove(file.Path); err != nil {
+			return remove[:i], err
+		}
+	}
+	return remove, nil
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f kB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+)
+
+// file in the backups directory the retention policy is saved to, it is kept out of the
+// database so restoring a backup does not change it
+const policyFile = "policy.json"
+
+const timeFormat = "20060102-150405"
+
+// backups in the backups directory are named acc-<label>-<time>.db, the label says why
+// the backup was made (manual, import, v10 for the schema version before a migration...)
+var namePattern = regexp.MustCompile(`^acc-([a-z0-9-]+)-(\d{8}-\d{6})\.db$`)
+
+// File is a backup in the backups directory
+type File struct {
+	Path  string
+	Label string
+	Time  time.Time
+	Size  int64
+}
+
+// Policy decides which backups in the backups directory are kept: the KeepLast newest,
+// the newest of each of the last KeepDaily days and the newest of each of the last
+// KeepMonthly months
+type Policy struct {
+	KeepLast    int `json:"keep_last"`
+	KeepDaily   int `json:"keep_daily"`
+	KeepMonthly int `json:"keep_monthly"`
+}
+
+var DefaultPolicy = Policy{KeepLast: 5, KeepDaily: 7, KeepMonthly: 12}
+
+func (p Policy) Validate() error {
+	if p.KeepLast < 1 {
+		return errors.New("invalid policy. at least the last backup must be kept")
+	}
+	if p.KeepDaily < 0 || p.KeepMonthly < 0 {
+		return errors.New("invalid policy. the number of days and months to keep cannot be negative")
+	}
+	return nil
+}
+
+// Dir is the backups directory of the database in use. Ledgers keep it next to their
+// database, other databases (--db, $ACC_DB) could be next to anything so theirs is in the app
+// directory, named after a hash of the database path
+func Dir() string {
+	dir := filepath.Dir(database.DBPATH)
+	if database.DBPATH == utils.LedgerPath(utils.DefaultLedger) || database.DBPATH == utils.LedgerPath(filepath.Base(dir)) {
+		return filepath.Join(dir, "backups")
+	}
+	sum := sha256.Sum256([]byte(database.DBPATH))
+	return filepath.Join(utils.APPDIR(), "db-backups", hex.EncodeToString(sum[:])[:16])
+}
+
+// NewPath returns an unused path in dir for a backup made at t
+func NewPath(dir string, label string, t time.Time) string {
+	name := label
+	for i := 2; ; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("acc-%s-%s.db", name, t.Format(timeFormat)))
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		name = fmt.Sprintf("%s-%d", label, i)
+	}
+}
+
+// Parse reads the label and time of a backup from its file name
+func Parse(path string) (File, bool) {
+	match := namePattern.FindStringSubmatch(filepath.Base(path))
+	if match == nil {
+		return File{}, false
+	}
+	t, err := time.ParseInLocation(timeFormat, match[2], time.Local)
+	if err != nil {
+		return File{}, false
+	}
+	return File{Path: path, Label: match[1], Time: t}, true
+}
+
+// List returns the backups in dir, newest first. Other files in dir are left out.
+func List(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, entry := range entries {
+		file, ok := Parse(filepath.Join(dir, entry.Name()))
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			file.Size = info.Size()
+		}
+		files = append(files, file)
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Time.After(files[j].Time)
+	})
+	return files, nil
+}
+
+// Prune splits files (newest first) into the ones policy keeps and the ones it removes
+func Prune(files []File, policy Policy, now time.Time) (keep []File, remove []File) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	firstDay := today.AddDate(0, 0, 1-policy.KeepDaily)
+	firstMonth := time.Date(now.Year(), now.Month()-time.Month(policy.KeepMonthly-1), 1, 0, 0, 0, 0, now.Location())
+	days := map[string]bool{}
+	months := map[string]bool{}
+	for i, file := range files {
+		kept := i < policy.KeepLast
+		// files are newest first, so the first one seen of a day or month is its newest
+		day, month := file.Time.Format("2006-01-02"), file.Time.Format("2006-01")
+		if policy.KeepDaily > 0 && !file.Time.Before(firstDay) && !days[day] {
+			days[day] = true
+			kept = true
+		}
+		if policy.KeepMonthly > 0 && !file.Time.Before(firstMonth) && !months[month] {
+			months[month] = true
+			kept = true
+		}
+		if kept {
+			keep = append(keep, file)
+		} else {
+			remove = append(remove, file)
+		}
+	}
+	return keep, remove
+}
+
+// LoadPolicy reads the retention policy saved in dir with acc backup policy, or returns
+// the default one
+func LoadPolicy(dir string) (Policy, error) {
+	b, err := os.ReadFile(filepath.Join(dir, policyFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultPolicy, nil
+	}
+	if err != nil {
+		return DefaultPolicy, err
+	}
+	policy := DefaultPolicy
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return DefaultPolicy, fmt.Errorf("invalid backup policy %s: %w", filepath.Join(dir, policyFile), err)
+	}
+	return policy, nil
+}
+
+func SavePolicy(dir string, policy Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, policyFile), append(b, '\n'), 0644)
+}
@@ -0,0 +1,98 @@
+package backup_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/backup"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+)
+
+func backupAt(t *testing.T, stamp string) backup.File {
+	file, ok := backup.Parse(filepath.Join("backups", "acc-manual-"+stamp+".db"))
+	if !ok {
+		t.Fatal("failed to parse backup", stamp)
+	}
+	return file
+}
+
+func TestParse(t *testing.T) {
+	file, ok := backup.Parse("acc-v10-20261018-085456.db")
+	if !ok || file.Label != "v10" || file.Time.Format("2006-01-02 15:04:05") != "2026-10-18 08:54:56" {
+		t.Error("expected a v10 backup of 2026-10-18 08:54:56, got", file)
+	}
+	for _, name := range []string{"acc.db", "acc-manual.db", "acc-manual-20261018.db", "policy.json"} {
+		if _, ok := backup.Parse(name); ok {
+			t.Error("expected", name, "not to be a backup")
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	// newest first, as List returns them
+	var files []backup.File
+	for _, stamp := range []string{
+		"20261018-120000",
+		"20261018-090000",
+		"20261017-200000",
+		"20261017-100000",
+		"20261010-100000",
+		"20261001-100000",
+		"20260915-100000",
+		"20260901-100000",
+		"20251120-100000",
+		"20250101-100000",
+	} {
+		files = append(files, backupAt(t, stamp))
+	}
+	now := time.Date(2026, 10, 18, 13, 0, 0, 0, time.Local)
+	keep, remove := backup.Prune(files, backup.Policy{KeepLast: 2, KeepDaily: 7, KeepMonthly: 12}, now)
+	var kept []string
+	for _, file := range keep {
+		kept = append(kept, file.Time.Format("20060102-150405"))
+	}
+	// the last two, the newest of 10-17 (within 7 days) and the newest of september and
+	// of 2025-11 (within 12 months)
+	want := []string{"20261018-120000", "20261018-090000", "20261017-200000", "20260915-100000", "20251120-100000"}
+	if len(kept) != len(want) {
+		t.Fatal("expected to keep", want, "got", kept)
+	}
+	for i := range want {
+		if kept[i] != want[i] {
+			t.Error("expected to keep", want, "got", kept)
+			break
+		}
+	}
+	if len(keep)+len(remove) != len(files) {
+		t.Error("expected every backup to be kept or removed")
+	}
+	if err := (backup.Policy{KeepLast: 0, KeepDaily: 7}).Validate(); err == nil {
+		t.Error("expected a policy that keeps no last backup to be invalid")
+	}
+}
+
+func TestDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("ACC_HOME", home)
+	defer func(path string) { database.DBPATH = path }(database.DBPATH)
+	database.DBPATH = utils.LedgerPath(utils.DefaultLedger)
+	if dir := backup.Dir(); dir != filepath.Join(home, "backups") {
+		t.Error("expected the default ledger's backups next to it, got", dir)
+	}
+	database.DBPATH = utils.LedgerPath("work")
+	if dir := backup.Dir(); dir != filepath.Join(home, "ledgers", "work", "backups") {
+		t.Error("expected the work ledger's backups next to it, got", dir)
+	}
+	// a database given with --db is kept away from the files around it
+	database.DBPATH = filepath.Join(t.TempDir(), "taxes", "acc.db")
+	dir := backup.Dir()
+	if filepath.Dir(dir) != filepath.Join(home, "db-backups") {
+		t.Error("expected the backups in the app directory, got", dir)
+	}
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if other := backup.Dir(); other == dir || filepath.Dir(other) != filepath.Join(home, "db-backups") {
+		t.Error("expected another database to get its own backups directory, got", other)
+	}
+}
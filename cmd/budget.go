@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/budget"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Limit the expenses of categories per week, month or year",
+}
+
+var budgetSetCmd = &cobra.Command{
+	Use:   "set <category> <amount>",
+	Short: "Set the budget of a category, its subcategories count towards it",
+	Long: `Set the budget of a category in the base currency, expenses in its subcategories count
+towards it. Setting the budget of a category again replaces it.`,
+	Example: `acc budget set Food 400 --period month
+acc budget set "Food > Dining" 50 --period week --rollover`,
+	Args: cobra.ExactArgs(2),
+	Run:  BudgetSet,
+}
+
+var budgetLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List budgets",
+	Args:  cobra.NoArgs,
+	Run:   BudgetLs,
+}
+
+var budgetRmCmd = &cobra.Command{
+	Use:   "rm <category>",
+	Short: "Remove the budget of a category",
+	Args:  cobra.ExactArgs(1),
+	Run:   BudgetRm,
+}
+
+var budgetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Compare budgets with the expenses of the current period",
+	Long: `Compare budgets with the expenses of the current period, or of the period --date falls in.
+acc exits with status 1 when any budget is exceeded or the status cannot be shown.`,
+	Example: `acc budget status
+acc budget status --date lastmonth || notify-send "over budget"`,
+	Args: cobra.NoArgs,
+	RunE: BudgetStatus,
+	// the exit status reports overspending, which is not a usage error
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	RootCmd.AddCommand(budgetCmd)
+	budgetCmd.AddCommand(budgetSetCmd, budgetLsCmd, budgetRmCmd, budgetStatusCmd)
+	budgetSetCmd.Flags().StringP("period", "p", "month", "budget period (week, month or year)")
+	budgetSetCmd.Flags().Bool("rollover", false, "carry unspent amounts over into the next period")
+	budgetStatusCmd.Flags().StringP("date", "d", "", "show the period this date falls in, e.g. lastmonth or 2026-01-15 (default: today)")
+}
+
+func BudgetSet(cmd *cobra.Command, args []string) {
+	amount, err := money.Parse(args[1])
+	if err != nil {
+		fmt.Println(utils.AmountSyntaxError)
+		return
+	}
+	period, _ := cmd.Flags().GetString("period")
+	if err := budget.ValidatePeriod(period); err != nil {
+		fmt.Println(err)
+		return
+	}
+	categoryID, err := database.NewCategoryRepository().FindCategory(args[0])
+	if err != nil {
+		fmt.Println(err)
+		fmt.Println("Create it first with: acc category add \"" + args[0] + "\"")
+		return
+	}
+	rollover, _ := cmd.Flags().GetBool("rollover")
+	b := database.Budget{
+		CategoryID: categoryID,
+		Category:   args[0],
+		Amount:     amount,
+		Period:     period,
+		Rollover:   rollover,
+		StartDate:  budget.PeriodStart(time.Now().UTC(), period).Format(database.ScheduleDateFormat),
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Printf("Would set the budget of %s to %s per %s\n", args[0], amount, period)
+		return
+	}
+	if err := database.NewBudgetRepository().SetBudget(b); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func BudgetLs(cmd *cobra.Command, args []string) {
+	budgets, err := database.NewBudgetRepository().GetBudgets()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	currency, err := database.NewFxRepository().GetBaseCurrency()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Category", "Amount", "Period", "Rollover", "Since"})
+	for _, b := range budgets {
+		rollover := "no"
+		if b.Rollover {
+			rollover = "yes"
+		}
+		t.AppendRow(table.Row{b.Category, utils.FormatAmount(b.Amount, currency), b.Period, rollover, b.StartDate})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
+
+func BudgetRm(cmd *cobra.Command, args []string) {
+	if err := database.NewBudgetRepository().DeleteBudget(args[0]); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func BudgetStatus(cmd *cobra.Command, args []string) error {
+	date, err := budget.ParseDate(cmd.Flag("date").Value.String(), time.Now().UTC())
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	budgets, err := database.NewBudgetRepository().GetBudgets()
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	if len(budgets) == 0 {
+		fmt.Println("No budgets yet, add one with: acc budget set <category> <amount>")
+		return nil
+	}
+	currency, err := database.NewFxRepository().GetBaseCurrency()
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	statuses, missing, err := budget.Statuses(budgets, date)
+	if err != nil {
+		fmt.Println(err)
+		return err
+	}
+	budget.Render(statuses, currency)
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "%d transactions skipped, no exchange rate to %s (see acc fx set)\n", missing, currency)
+	}
+	exceeded := 0
+	for _, s := range statuses {
+		if s.Exceeded() {
+			exceeded++
+		}
+	}
+	if exceeded == 0 {
+		return nil
+	}
+	err = fmt.Errorf("%d of %d budgets exceeded", exceeded, len(statuses))
+	fmt.Println(err)
+	return err
+}
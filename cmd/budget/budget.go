@@ -0,0 +1,101 @@
+package budget
+
+import (
+	"time"
+
+	"github.com/elliot40404/acc/cmd/stats"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/itlightning/dateparse"
+)
+
+// Status compares the expenses of one budget period with the amount available in it
+type Status struct {
+	database.Budget
+	// Label names the period, e.g. 2026-03 or 2026-W10
+	Label string `json:"label"`
+	// Carried is the amount rolled over from earlier periods
+	Carried   money.Money `json:"carried"`
+	Available money.Money `json:"available"`
+	Spent     money.Money `json:"spent"`
+	Remaining money.Money `json:"remaining"`
+}
+
+func (s Status) Exceeded() bool {
+	return s.Spent > s.Available
+}
+
+// PeriodStart is the first day of the week, month or (fiscal) year t falls in, see
+// utils.WeekStart and utils.FiscalYearStart
+func PeriodStart(t time.Time, period string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "week":
+		return utils.StartOfWeek(day)
+	case "year":
+		return utils.StartOfFiscalYear(day)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextPeriod is the first day of the period after the one starting on start
+func NextPeriod(start time.Time, period string) time.Time {
+	switch period {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "year":
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// Span returns the days whose expenses Evaluate needs for the period date falls in. Budgets
+// with rollover reach back to the period of their start date.
+func Span(budget database.Budget, date time.Time) (time.Time, time.Time) {
+	current := PeriodStart(date, budget.Period)
+	first := current
+	if budget.Rollover {
+		if start, err := time.Parse(database.ScheduleDateFormat, budget.StartDate); err == nil {
+			if start := PeriodStart(start, budget.Period); start.Before(current) {
+				first = start
+			}
+		}
+	}
+	return first, NextPeriod(current, budget.Period).AddDate(0, 0, -1)
+}
+
+// Evaluate works out the status of the budget in the period date falls in. expenses are the
+// base currency expenses of the budget's categories within Span. Unspent amounts of earlier
+// periods are carried over when the budget rolls over, overspending is not carried.
+func Evaluate(budget database.Budget, expenses []database.Transaction, date time.Time) Status {
+	first, _ := Span(budget, date)
+	current := PeriodStart(date, budget.Period)
+	spent := map[time.Time]money.Money{}
+	for _, expense := range expenses {
+		t, err := dateparse.ParseAny(expense.OccurredAt)
+		if err != nil {
+			continue
+		}
+		spent[PeriodStart(t, budget.Period)] += expense.Amount
+	}
+	var carried money.Money
+	for start := first; start.Before(current); start = NextPeriod(start, budget.Period) {
+		if left := budget.Amount + carried - spent[start]; left > 0 {
+			carried = left
+		} else {
+			carried = 0
+		}
+	}
+	available := budget.Amount + carried
+	return Status{
+		Budget:    budget,
+		Label:     stats.FormatPeriod(current, budget.Period),
+		Carried:   carried,
+		Available: available,
+		Spent:     spent[current],
+		Remaining: available - spent[current],
+	}
+}
@@ -0,0 +1,56 @@
+package budget_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/budget"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+func day(date string) time.Time {
+	t, _ := time.Parse(database.ScheduleDateFormat, date)
+	return t
+}
+
+func TestPeriodStart(t *testing.T) {
+	cases := map[string]string{
+		"week":  "2026-03-09",
+		"month": "2026-03-01",
+		"year":  "2026-01-01",
+	}
+	// 2026-03-15 is a sunday, weeks start on monday
+	for period, want := range cases {
+		if r := budget.PeriodStart(day("2026-03-15"), period).Format(database.ScheduleDateFormat); r != want {
+			t.Error("expected", want, "for", period, "got", r)
+		}
+	}
+	if r := budget.NextPeriod(day("2026-01-01"), "month"); !r.Equal(day("2026-02-01")) {
+		t.Error("expected 2026-02-01, got", r)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	b := database.Budget{Amount: money.Money(10000), Period: "month", StartDate: "2026-01-01"}
+	expenses := []database.Transaction{
+		{Amount: money.Money(6000), OccurredAt: "2026-01-10T12:00:00Z"},
+		{Amount: money.Money(15000), OccurredAt: "2026-02-03T12:00:00Z"},
+		{Amount: money.Money(11000), OccurredAt: "2026-03-20T12:00:00Z"},
+	}
+	s := budget.Evaluate(b, expenses, day("2026-03-31"))
+	if s.Label != "2026-03" || s.Spent != 11000 || s.Remaining != -1000 || !s.Exceeded() {
+		t.Error("expected 110.00 of 100.00 spent in 2026-03, got", s)
+	}
+	// 40.00 is left in january, february is overspent and carries nothing into march
+	b.Rollover = true
+	if s := budget.Evaluate(b, expenses, day("2026-02-14")); s.Carried != 4000 || s.Remaining != -1000 {
+		t.Error("expected 40.00 carried into february, got", s)
+	}
+	if s := budget.Evaluate(b, expenses, day("2026-03-31")); s.Carried != 0 || !s.Exceeded() {
+		t.Error("expected nothing carried into march, got", s)
+	}
+	if from, to := budget.Span(b, day("2026-03-31")); !from.Equal(day("2026-01-01")) || !to.Equal(day("2026-03-31")) {
+		t.Error("expected 2026-01-01 to 2026-03-31, got", from, to)
+	}
+}
@@ -0,0 +1,98 @@
+package budget
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/elliot40404/acc/cmd/stats"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+const barWidth = 20
+
+var (
+	underStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	nearStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	overStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	emptyStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "250", Dark: "238"})
+)
+
+// Statuses evaluates every budget for the period date falls in, missing counts the expenses
+// left out because they have no exchange rate to the base currency
+func Statuses(budgets []database.Budget, date time.Time) ([]Status, int, error) {
+	repo := database.NewTransactionRepository()
+	statuses := make([]Status, 0, len(budgets))
+	missing := 0
+	for _, budget := range budgets {
+		from, to := Span(budget, date)
+		expenses, err := repo.GetTransactionsWithConfig(database.TransactionConfig{
+			TxType:     "expense",
+			CategoryID: budget.CategoryID,
+			Date:       from.Format(database.ScheduleDateFormat) + ":" + to.Format(database.ScheduleDateFormat),
+			Page:       1,
+			Limit:      1,
+			All:        true,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		expenses, skipped := stats.ToBaseCurrency(expenses)
+		missing += skipped
+		statuses = append(statuses, Evaluate(budget, expenses, date))
+	}
+	return statuses, missing, nil
+}
+
+func Render(statuses []Status, currency string) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Category", "Period", "Budget", "Rollover", "Spent", "Remaining", "Progress"})
+	for _, s := range statuses {
+		rollover := ""
+		if s.Rollover {
+			rollover = utils.FormatAmount(s.Carried, currency)
+		}
+		t.AppendRow(table.Row{
+			s.Category,
+			s.Label,
+			utils.FormatAmount(s.Amount, currency),
+			rollover,
+			utils.FormatAmount(s.Spent, currency),
+			utils.FormatAmount(s.Remaining, currency),
+			progressBar(s.Spent, s.Available),
+		})
+	}
+	t.SetTitle("Amounts in " + currency)
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
+
+// progressBar fills up with the share of available that is spent, it turns yellow from 80%
+// and red once the budget is exceeded
+func progressBar(spent money.Money, available money.Money) string {
+	ratio := 1.0
+	if available > 0 {
+		ratio = float64(spent) / float64(available)
+	}
+	filled := int(ratio*barWidth + 0.5)
+	if filled > barWidth {
+		filled = barWidth
+	} else if filled < 0 {
+		filled = 0
+	}
+	style := underStyle
+	switch {
+	case spent > available:
+		style = overStyle
+	case ratio >= 0.8:
+		style = nearStyle
+	}
+	bar := style.Render(strings.Repeat("█", filled)) + emptyStyle.Render(strings.Repeat("░", barWidth-filled))
+	return fmt.Sprintf("%s %3.0f%%", bar, ratio*100)
+}
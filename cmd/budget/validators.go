@@ -0,0 +1,39 @@
+package budget
+
+import (
+	"errors"
+	"time"
+
+	"github.com/elliot40404/acc/pkg/utils"
+)
+
+var validPeriods = []string{
+	"week",
+	"month",
+	"year",
+}
+
+func ValidatePeriod(period string) error {
+	for _, validPeriod := range validPeriods {
+		if period == validPeriod {
+			return nil
+		}
+	}
+	return errors.New("invalid period. period must be one of 'week', 'month' or 'year'")
+}
+
+// ParseDate resolves the date a status is shown for, the period builtins like lastmonth
+// stand for a day in that period
+func ParseDate(date string, now time.Time) (time.Time, error) {
+	switch date {
+	case "", "today", "thisweek", "thismonth", "thisyear":
+		return now, nil
+	case "lastweek":
+		return now.AddDate(0, 0, -7), nil
+	case "lastmonth":
+		return PeriodStart(now, "month").AddDate(0, 0, -1), nil
+	case "lastyear":
+		return PeriodStart(now, "year").AddDate(0, 0, -1), nil
+	}
+	return utils.ParseDate(date)
+}
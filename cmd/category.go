@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/cmd/stats"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var categoryCmd = &cobra.Command{
+	Use:     "category",
+	Aliases: []string{"cat"},
+	Short:   "Manage transaction categories",
+}
+
+var categoryAddCmd = &cobra.Command{
+	Use:     "add <path>",
+	Short:   "Add a category, missing parents are created as well",
+	Example: `acc category add "Food > Groceries"`,
+	Args:    cobra.ExactArgs(1),
+	Run:     CategoryAdd,
+}
+
+var categoryLsCmd = &cobra.Command{
+	Use:     "ls",
+	Short:   "List categories with totals rolled up from their subcategories",
+	Example: `acc category ls -d thismonth --depth 1`,
+	Run:     CategoryLs,
+}
+
+var categoryRmCmd = &cobra.Command{
+	Use:   "rm <path>",
+	Short: "Remove a category, its transactions become uncategorized",
+	Args:  cobra.ExactArgs(1),
+	Run:   CategoryRm,
+}
+
+var categoryMvCmd = &cobra.Command{
+	Use:     "mv <path> <new-path>",
+	Short:   "Rename or move a category",
+	Example: `acc category mv "Food > Takeout" "Dining"`,
+	Args:    cobra.ExactArgs(2),
+	Run:     CategoryMv,
+}
+
+func init() {
+	RootCmd.AddCommand(categoryCmd)
+	categoryCmd.AddCommand(categoryAddCmd, categoryLsCmd, categoryRmCmd, categoryMvCmd)
+	categoryLsCmd.Flags().StringP("date", "d", "", "only count transactions in date or date-range")
+	categoryLsCmd.Flags().StringP("type", "t", "", "only count transactions of type (income, expense)")
+	categoryLsCmd.Flags().Int("depth", 0, "only show this many levels (default: all)")
+}
+
+func CategoryAdd(cmd *cobra.Command, args []string) {
+	_, err := database.NewCategoryRepository().CreateCategory(args[0])
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func CategoryRm(cmd *cobra.Command, args []string) {
+	err := database.NewCategoryRepository().DeleteCategory(args[0])
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func CategoryMv(cmd *cobra.Command, args []string) {
+	err := database.NewCategoryRepository().MoveCategory(args[0], args[1])
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+type categoryNode struct {
+	category database.Category
+	children []*categoryNode
+	income   money.Money
+	expense  money.Money
+}
+
+func CategoryLs(cmd *cobra.Command, args []string) {
+	queryConfig := database.TransactionConfig{
+		Dry:     cmd.Flag("dry").Value.String() == "true",
+		Verbose: cmd.Flag("verbose").Value.String() == "true",
+		TxType:  cmd.Flag("type").Value.String(),
+		Page:    1,
+		Limit:   1,
+		All:     true,
+		Date:    cmd.Flag("date").Value.String(),
+	}
+	depth, _ := cmd.Flags().GetInt("depth")
+	if err := list.ValidateConfig(&queryConfig); err != nil {
+		fmt.Println(err)
+		return
+	}
+	categories, err := database.NewCategoryRepository().GetCategories()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	transactions, err := database.NewTransactionRepository().GetTransactionsWithConfig(queryConfig)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if queryConfig.Dry {
+		return
+	}
+	base, err := database.NewFxRepository().GetBaseCurrency()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	transactions, missing := stats.ToBaseCurrency(transactions)
+	nodes := map[int]*categoryNode{}
+	for _, category := range categories {
+		nodes[category.ID] = &categoryNode{category: category}
+	}
+	var roots []*categoryNode
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		parent := nodes[*category.ParentID]
+		parent.children = append(parent.children, node)
+	}
+	uncategorized := &categoryNode{category: database.Category{Name: "Uncategorized"}}
+	for _, transaction := range transactions {
+		// credit the category itself and every ancestor so parents show rolled up amounts
+		if transaction.CategoryID == nil {
+			addTotal(uncategorized, transaction)
+			continue
+		}
+		for id := transaction.CategoryID; id != nil; id = nodes[*id].category.ParentID {
+			addTotal(nodes[*id], transaction)
+		}
+	}
+	t := table.NewWriter()
+	t.SetTitle("Amounts in " + base)
+	t.AppendHeader(table.Row{"Category", "Income", "Expense", "Net"})
+	sortNodes(roots)
+	for _, root := range roots {
+		appendCategoryRows(t, root, 0, depth)
+	}
+	if uncategorized.income != 0 || uncategorized.expense != 0 {
+		appendCategoryRows(t, uncategorized, 0, depth)
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+	if missing > 0 {
+		fmt.Printf("%d transactions skipped, no exchange rate to %s (see acc fx set)\n", missing, base)
+	}
+}
+
+func addTotal(node *categoryNode, transaction database.Transaction) {
+	switch transaction.Type {
+	case "income":
+		node.income += transaction.Amount
+	case "expense":
+		node.expense += transaction.Amount
+	}
+}
+
+func sortNodes(nodes []*categoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].category.Name < nodes[j].category.Name
+	})
+}
+
+func appendCategoryRows(t table.Writer, node *categoryNode, level int, depth int) {
+	t.AppendRow(table.Row{
+		strings.Repeat("  ", level) + node.category.Name,
+		node.income.String(),
+		node.expense.String(),
+		(node.income - node.expense).String(),
+	})
+	if depth > 0 && level+1 >= depth {
+		return
+	}
+	sortNodes(node.children)
+	for _, child := range node.children {
+		appendCategoryRows(t, child, level+1, depth)
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elliot40404/acc/cmd/config"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change the defaults kept in the config file",
+	Long: `Show and change the defaults kept in the config file ($XDG_CONFIG_HOME/acc/config, by default
+~/.config/acc/config). Flags given on the command line take precedence over environment
+variables (e.g. ACC_LIST_LIMIT for list.limit), which take precedence over the config file.`,
+	Example: `acc config set list.limit 25
+acc config set list.human-time true
+acc config set list.sort date
+acc config ls`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run:   ConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:     "set <key> <value>",
+	Short:   "Save a setting to the config file",
+	Example: `acc config set list.columns id,date,amt,desc`,
+	Args:    cobra.ExactArgs(2),
+	Run:     ConfigSet,
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from the config file",
+	Args:  cobra.ExactArgs(1),
+	Run:   ConfigUnset,
+}
+
+var configLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the settings, their values and where the values come from",
+	Args:  cobra.NoArgs,
+	Run:   ConfigLs,
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configUnsetCmd, configLsCmd)
+}
+
+func ConfigGet(cmd *cobra.Command, args []string) {
+	c, err := config.Load(config.Path())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	value, _, err := c.Get(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(value)
+}
+
+func ConfigSet(cmd *cobra.Command, args []string) {
+	c, err := config.Load(config.Path())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := c.Set(args[0], args[1]); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Printf("Would set %s to %s\n", args[0], args[1])
+		return
+	}
+	if err := c.Save(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if setting, _ := config.Lookup(args[0]); os.Getenv(setting.Env()) != "" {
+		fmt.Fprintf(os.Stderr, "%s is set and takes precedence over the config file\n", setting.Env())
+	}
+}
+
+func ConfigUnset(cmd *cobra.Command, args []string) {
+	c, err := config.Load(config.Path())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := c.Unset(args[0]); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Printf("Would unset %s\n", args[0])
+		return
+	}
+	if err := c.Save(); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func ConfigLs(cmd *cobra.Command, args []string) {
+	path := config.Path()
+	c, err := config.Load(path)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Key", "Value", "Source", "Description"})
+	for _, setting := range config.Settings {
+		value, source, err := c.Get(setting.Key)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if source == config.SourceEnv {
+			source = config.Source(setting.Env())
+		}
+		t.AppendRow(table.Row{setting.Key, value, source, setting.Description})
+	}
+	t.SetTitle(path)
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
@@ -0,0 +1,352 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+type kind int
+
+const (
+	kindString kind = iota
+	kindInt
+	kindBool
+	kindList
+)
+
+// Setting is a preference kept in the config file. Its value comes from the flag on the
+// command line, the environment variable, the config file or the default, in that order.
+type Setting struct {
+	Key         string
+	Default     string
+	Description string
+	// Flag of Commands the setting is the default of, empty for preferences without a flag
+	Flag     string
+	Commands []string
+	kind     kind
+	validate func(value string) error
+}
+
+// Env is the environment variable of the setting, e.g. ACC_LIST_LIMIT
+func (s Setting) Env() string {
+	return "ACC_" + strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(s.Key))
+}
+
+// Source tells where the value of a setting comes from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceConfig  Source = "config"
+	SourceEnv     Source = "env"
+)
+
+var Settings = []Setting{
+	{
+		Key:         "list.limit",
+		Default:     "10",
+		Description: "transactions per page of acc list and acc serve",
+		Flag:        "limit",
+		Commands:    []string{"list", "search", "serve"},
+		kind:        kindInt,
+		validate: func(value string) error {
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				return errors.New("invalid limit. limit must be greater than 0")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         "list.sort",
+		Default:     "",
+		Description: "sort of acc list, date or amt (default: date)",
+		Flag:        "sort",
+		Commands:    []string{"list"},
+		validate:    list.ValidateSort,
+	},
+	{
+		Key:         "list.columns",
+		Default:     "",
+		Description: "columns of the table of acc list, e.g. id,date,amt,desc (default: all)",
+		Flag:        "columns",
+		Commands:    []string{"list", "search"},
+		kind:        kindList,
+		validate: func(value string) error {
+			return list.ValidateColumns(splitList(value))
+		},
+	},
+	{
+		Key:         "list.human-time",
+		Default:     "false",
+		Description: "show human friendly times in acc list",
+		Flag:        "htime",
+		Commands:    []string{"list", "search"},
+		kind:        kindBool,
+		validate:    validateBool,
+	},
+	{
+		Key:         "list.format",
+		Default:     "table",
+		Description: "output format of acc list (table, json, csv, qif, ledger, hledger or beancount)",
+		Flag:        "format",
+		Commands:    []string{"list", "search"},
+		validate:    list.ValidateFormat,
+	},
+	{
+		Key:         "currency-symbol",
+		Default:     "false",
+		Description: "show amounts in tables with the symbol of their currency, e.g. $12.00",
+		kind:        kindBool,
+		validate:    validateBool,
+	},
+	{
+		Key:         "date-format",
+		Default:     "",
+		Description: "layout of dates in tables, written as the date 2006-01-02 15:04, e.g. 02.01.2006 (default: as stored)",
+		validate: func(value string) error {
+			if value != "" && time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(value) == value {
+				return errors.New("invalid date format. write it as the date 2006-01-02 15:04 should be shown, e.g. 02.01.2006 or Jan 2, 2006")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         "week-start",
+		Default:     "monday",
+		Description: "first day of the week for thisweek, lastweek, weekly budgets and stats",
+		validate: func(value string) error {
+			_, err := utils.ParseWeekday(value)
+			return err
+		},
+	},
+	{
+		Key:         "fiscal-year-start",
+		Default:     "january",
+		Description: "month the year starts in for thisyear, lastyear, yearly budgets and stats",
+		validate: func(value string) error {
+			_, err := utils.ParseMonth(value)
+			return err
+		},
+	},
+}
+
+func validateBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return errors.New("invalid value. expected true or false")
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func Lookup(key string) (Setting, error) {
+	for _, setting := range Settings {
+		if setting.Key == key {
+			return setting, nil
+		}
+	}
+	return Setting{}, fmt.Errorf("unknown setting '%s', see acc config ls", key)
+}
+
+// Path is acc/config in $XDG_CONFIG_HOME (default ~/.config)
+func Path() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(dir) {
+		homedir, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(homedir, ".config")
+	}
+	return filepath.Join(dir, "acc", "config")
+}
+
+// Config is the config file, a JSON object of setting keys and values
+type Config struct {
+	path   string
+	values map[string]string
+	// unknown holds keys of the file that are no setting, e.g. of a newer acc. they are kept
+	// when the file is saved until they are unset
+	unknown map[string]interface{}
+}
+
+// Load reads the config file at path, a missing file is an empty config. Unknown keys are
+// ignored with a warning on stderr so acc config unset can still remove them.
+func Load(path string) (*Config, error) {
+	c := &Config{path: path, values: map[string]string{}, unknown: map[string]interface{}{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	for key, value := range values {
+		setting, err := Lookup(key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s, ignoring it\n", path, err)
+			c.unknown[key] = value
+			continue
+		}
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case bool:
+			s = strconv.FormatBool(v)
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			s = strings.Join(items, ",")
+		default:
+			return nil, fmt.Errorf("%s: invalid value of %s", path, key)
+		}
+		if err := setting.validate(s); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+		c.values[key] = s
+	}
+	return c, nil
+}
+
+// Get returns the value of the setting key and where it comes from
+func (c *Config) Get(key string) (string, Source, error) {
+	setting, err := Lookup(key)
+	if err != nil {
+		return "", "", err
+	}
+	if value, ok := os.LookupEnv(setting.Env()); ok {
+		if err := setting.validate(value); err != nil {
+			return "", "", fmt.Errorf("%s: %w", setting.Env(), err)
+		}
+		return value, SourceEnv, nil
+	}
+	if value, ok := c.values[key]; ok {
+		return value, SourceConfig, nil
+	}
+	return setting.Default, SourceDefault, nil
+}
+
+func (c *Config) Set(key string, value string) error {
+	setting, err := Lookup(key)
+	if err != nil {
+		return err
+	}
+	if setting.kind == kindList {
+		value = strings.Join(splitList(value), ",")
+	}
+	if err := setting.validate(value); err != nil {
+		return err
+	}
+	c.values[key] = value
+	return nil
+}
+
+func (c *Config) Unset(key string) error {
+	if _, ok := c.unknown[key]; ok {
+		delete(c.unknown, key)
+		return nil
+	}
+	if _, err := Lookup(key); err != nil {
+		return err
+	}
+	delete(c.values, key)
+	return nil
+}
+
+// Save writes the config file, values are stored with the JSON type of their setting
+func (c *Config) Save() error {
+	values := map[string]interface{}{}
+	for key, value := range c.unknown {
+		values[key] = value
+	}
+	for key, value := range c.values {
+		setting, _ := Lookup(key)
+		switch setting.kind {
+		case kindInt:
+			values[key], _ = strconv.Atoi(value)
+		case kindBool:
+			values[key], _ = strconv.ParseBool(value)
+		case kindList:
+			values[key] = splitList(value)
+		default:
+			values[key] = value
+		}
+	}
+	b, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(b, '\n'), 0644)
+}
+
+// Apply sets the flags of cmd that were not given on the command line and the display and
+// calendar preferences of utils
+func (c *Config) Apply(cmd *cobra.Command) error {
+	for _, setting := range Settings {
+		value, source, err := c.Get(setting.Key)
+		if err != nil {
+			return err
+		}
+		switch setting.Key {
+		case "currency-symbol":
+			utils.CurrencySymbol, _ = strconv.ParseBool(value)
+		case "date-format":
+			utils.DateFormat = value
+		case "week-start":
+			utils.WeekStart, _ = utils.ParseWeekday(value)
+		case "fiscal-year-start":
+			utils.FiscalYearStart, _ = utils.ParseMonth(value)
+		}
+		if setting.Flag == "" || source == SourceDefault || !setting.appliesTo(cmd) {
+			continue
+		}
+		f := cmd.Flags().Lookup(setting.Flag)
+		if f == nil || f.Changed {
+			continue
+		}
+		// set through the value so the flag still counts as not given
+		if err := f.Value.Set(value); err != nil {
+			return fmt.Errorf("%s: %w", setting.Key, err)
+		}
+	}
+	return nil
+}
+
+func (s Setting) appliesTo(cmd *cobra.Command) bool {
+	for _, name := range s.Commands {
+		if cmd.Name() == name && cmd.HasParent() && !cmd.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
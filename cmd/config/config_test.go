@@ -0,0 +1,117 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/config"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acc", "config")
+	c, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, source, _ := c.Get("list.limit"); value != "10" || source != config.SourceDefault {
+		t.Error("expected the default limit 10, got", value, source)
+	}
+	for key, value := range map[string]string{"list.limit": "0", "list.sort": "price", "week-start": "someday", "date-format": "dd.mm", "nope": "1"} {
+		if err := c.Set(key, value); err == nil {
+			t.Error("expected", key, value, "to be rejected")
+		}
+	}
+	settings := map[string]string{"list.limit": "25", "list.columns": "id, date,amt", "list.human-time": "true", "fiscal-year-start": "apr", "date-format": "02.01.2006"}
+	for key, value := range settings {
+		if err := c.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	c, err = config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, source, _ := c.Get("list.columns"); value != "id,date,amt" || source != config.SourceConfig {
+		t.Error("expected the columns of the config file, got", value, source)
+	}
+	t.Setenv("ACC_LIST_LIMIT", "50")
+	if value, source, _ := c.Get("list.limit"); value != "50" || source != config.SourceEnv {
+		t.Error("expected the limit of the environment, got", value, source)
+	}
+	if err := os.WriteFile(path, []byte(`{"list.limit": -1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(path); err == nil {
+		t.Error("expected an invalid config file to be rejected")
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	c, err := config.Load(config.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("list.limit", "25")
+	c.Set("list.human-time", "true")
+	c.Set("week-start", "sunday")
+	root := &cobra.Command{Use: "acc"}
+	list := &cobra.Command{Use: "list", Run: func(cmd *cobra.Command, args []string) {}}
+	list.Flags().IntP("limit", "l", 10, "")
+	list.Flags().BoolP("htime", "H", false, "")
+	root.AddCommand(list)
+	list.ParseFlags([]string{"-l", "5"})
+	if err := c.Apply(list); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { utils.WeekStart = time.Monday }()
+	// flags given on the command line win over the config file
+	if limit, _ := list.Flags().GetInt("limit"); limit != 5 {
+		t.Error("expected the limit of the command line, got", limit)
+	}
+	if htime, _ := list.Flags().GetBool("htime"); !htime || list.Flags().Changed("htime") {
+		t.Error("expected human time from the config file without marking the flag as given")
+	}
+	if utils.WeekStart != time.Sunday {
+		t.Error("expected weeks to start on sunday, got", utils.WeekStart)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(`{"bogus": 1, "list.limit": 25}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := config.Load(path)
+	if err != nil {
+		t.Fatal("expected an unknown key to be ignored, got", err)
+	}
+	if value, _, _ := c.Get("list.limit"); value != "25" {
+		t.Error("expected the limit of the config file, got", value)
+	}
+	// saving keeps the unknown key, unsetting it removes it
+	c.Set("list.sort", "amt")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); !strings.Contains(string(b), `"bogus": 1`) {
+		t.Error("expected the unknown key to be kept, got", string(b))
+	}
+	if err := c.Unset("bogus"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); strings.Contains(string(b), "bogus") {
+		t.Error("expected the unknown key to be removed, got", string(b))
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var editCmd = &cobra.Command{
+	Use:     "edit",
+	Short:   "Edit an existing transaction",
+	Example: `acc edit -i 3 -a 1200 -d "Paycheck (bonus)"`,
+	Run:     Edit,
+}
+
+func init() {
+	RootCmd.AddCommand(editCmd)
+	editCmd.Flags().IntP("id", "i", 0, "Id of the transaction to edit")
+	editCmd.Flags().StringP("type", "t", "", "Type of transaction (income, expense or transfer)")
+	editCmd.Flags().StringP("description", "d", "", "Description")
+	editCmd.Flags().String("note", "", "Longer note, an empty note removes it")
+	editCmd.Flags().StringP("amount", "a", "", "Amount (e.g. 12.34)")
+	editCmd.Flags().String("currency", "", "Currency of the amount, e.g. EUR")
+	editCmd.Flags().String("account", "", "Account the transaction is booked on")
+	editCmd.Flags().String("to", "", "Destination account of a transfer")
+	editCmd.Flags().StringP("category", "c", "", "Category path (e.g. \"Food > Groceries\")")
+	editCmd.Flags().StringSlice("tag", []string{}, "Add a tag, prefix with ! to remove it (e.g. --tag trip --tag '!work')")
+	editCmd.Flags().StringP("date", "D", "", "Date of the transaction (e.g. 2024-01-31, yesterday)")
+	editCmd.MarkFlagRequired("id")
+}
+
+func Edit(cmd *cobra.Command, args []string) {
+	id, _ := cmd.Flags().GetInt("id")
+	uc := database.UpdateConfig{
+		Dry:     cmd.Flags().Changed("dry"),
+		Verbose: cmd.Flags().Changed("verbose"),
+		ID:      id,
+	}
+	if cmd.Flags().Changed("type") {
+		transactionType, _ := cmd.Flags().GetString("type")
+		if transactionType != "income" && transactionType != "expense" && transactionType != "transfer" {
+			fmt.Println("Supported transaction types: income, expense, transfer")
+			return
+		}
+		uc.Type = &transactionType
+	}
+	if cmd.Flags().Changed("description") {
+		description, _ := cmd.Flags().GetString("description")
+		uc.Description = &description
+	}
+	if cmd.Flags().Changed("note") {
+		note, _ := cmd.Flags().GetString("note")
+		uc.Note = &note
+	}
+	if cmd.Flags().Changed("amount") {
+		rawAmount, _ := cmd.Flags().GetString("amount")
+		amount, err := money.Parse(rawAmount)
+		if err != nil {
+			fmt.Println(utils.AmountSyntaxError)
+			return
+		}
+		uc.Amount = &amount
+	}
+	if cmd.Flags().Changed("date") {
+		date, _ := cmd.Flags().GetString("date")
+		t, err := utils.ParseDate(date)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		formatted := t.Format(utils.DBTimeFormat)
+		uc.Date = &formatted
+	}
+	if cmd.Flags().Changed("category") {
+		category, _ := cmd.Flags().GetString("category")
+		id, err := database.NewCategoryRepository().FindCategory(category)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		uc.CategoryID = &id
+	}
+	if cmd.Flags().Changed("currency") {
+		currency, _ := cmd.Flags().GetString("currency")
+		currency = strings.ToUpper(currency)
+		if err := list.ValidateCurrency(currency); err != nil {
+			fmt.Println(err)
+			return
+		}
+		uc.Currency = &currency
+	}
+	for _, flag := range []string{"account", "to"} {
+		if !cmd.Flags().Changed(flag) {
+			continue
+		}
+		name, _ := cmd.Flags().GetString(flag)
+		id, err := database.NewAccountRepository().FindAccount(name)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if flag == "account" {
+			uc.AccountID = &id
+		} else {
+			uc.ToAccountID = &id
+		}
+	}
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	if err := list.ValidateTags(tags, true); err != nil {
+		fmt.Println(err)
+		return
+	}
+	uc.AddTags, uc.RemoveTags = database.SplitTagFilter(tags)
+	if uc.Type == nil && uc.Description == nil && uc.Note == nil && uc.Amount == nil && uc.Currency == nil && uc.Date == nil && uc.CategoryID == nil &&
+		uc.AccountID == nil && uc.ToAccountID == nil && len(tags) == 0 {
+		fmt.Println("Please specify at least one of --type, --description, --note, --amount, --currency, --date, --category, --account, --to or --tag")
+		return
+	}
+	db := database.NewTransactionRepository()
+	if err := db.UpdateTransaction(uc); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var fxCmd = &cobra.Command{
+	Use:   "fx",
+	Short: "Manage the base currency and exchange rates",
+}
+
+var fxBaseCmd = &cobra.Command{
+	Use:     "base [currency]",
+	Short:   "Print or change the base currency reports are converted to",
+	Example: `acc fx base EUR`,
+	Args:    cobra.MaximumNArgs(1),
+	Run:     FxBase,
+}
+
+var fxSetCmd = &cobra.Command{
+	Use:     "set <from> <to> <rate>",
+	Short:   "Set the rate of one unit of <from> in <to>",
+	Example: `acc fx set EUR USD 1.0842 -D 2024-01-01`,
+	Args:    cobra.ExactArgs(3),
+	Run:     FxSet,
+}
+
+var fxLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List exchange rates",
+	Run:   FxLs,
+}
+
+var fxImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import exchange rates from a csv file with the columns date,from,to,rate",
+	Long: `Import exchange rates from a csv file with the columns date,from,to,rate.
+A header row is skipped, existing rates for the same pair and date are replaced.`,
+	Example: `acc fx import rates.csv`,
+	Args:    cobra.ExactArgs(1),
+	Run:     FxImport,
+}
+
+func init() {
+	RootCmd.AddCommand(fxCmd)
+	fxCmd.AddCommand(fxBaseCmd, fxSetCmd, fxLsCmd, fxImportCmd)
+	fxSetCmd.Flags().StringP("date", "D", "", "Date the rate is effective from (default: today)")
+}
+
+func FxBase(cmd *cobra.Command, args []string) {
+	repo := database.NewFxRepository()
+	if len(args) == 0 {
+		base, err := repo.GetBaseCurrency()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(base)
+		return
+	}
+	currency := strings.ToUpper(args[0])
+	if err := list.ValidateCurrency(currency); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := repo.SetBaseCurrency(currency); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func FxSet(cmd *cobra.Command, args []string) {
+	date := time.Now()
+	if d, _ := cmd.Flags().GetString("date"); d != "" {
+		var err error
+		date, err = utils.ParseDate(d)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	rate, err := newFxRate(date.Format("2006-01-02"), args[0], args[1], args[2])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := database.NewFxRepository().SetRates([]database.FxRate{rate}); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func FxLs(cmd *cobra.Command, args []string) {
+	rates, err := database.NewFxRepository().GetRates()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Date", "From", "To", "Rate"})
+	for _, rate := range rates {
+		t.AppendRow(table.Row{rate.Date, rate.From, rate.To, rate.Rate})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
+
+func FxImport(cmd *cobra.Command, args []string) {
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 4
+	r.TrimLeadingSpace = true
+	var rates []database.FxRate
+	for line := 1; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+		date, err := utils.ParseDate(record[0])
+		if err != nil {
+			fmt.Printf("line %d: %v\n", line, err)
+			return
+		}
+		rate, err := newFxRate(date.Format("2006-01-02"), record[1], record[2], record[3])
+		if err != nil {
+			fmt.Printf("line %d: %v\n", line, err)
+			return
+		}
+		rates = append(rates, rate)
+	}
+	if err := database.NewFxRepository().SetRates(rates); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Imported %d rates\n", len(rates))
+}
+
+func newFxRate(date string, from string, to string, rate string) (database.FxRate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	for _, currency := range []string{from, to} {
+		if err := list.ValidateCurrency(currency); err != nil {
+			return database.FxRate{}, err
+		}
+	}
+	if from == to {
+		return database.FxRate{}, fmt.Errorf("cannot set a rate from %s to itself", from)
+	}
+	return database.FxRate{From: from, To: to, Rate: strings.TrimSpace(rate), Date: date}, nil
+}
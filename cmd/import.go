@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/elliot40404/acc/cmd/importer"
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import transactions from files",
+}
+
+var importCsvCmd = &cobra.Command{
+	Use:   "csv <file>",
+	Short: "Import transactions from a csv file",
+	Long: `Import transactions from a csv file with a header row.
+
+By default the columns written by "acc list -f csv" are used, other files can be
+mapped with --map field=column. Fields are date, amount, desc, type, category,
+tags, account, to and currency, only date and amount are required. Without a
+type column negative amounts are imported as expenses and positive ones as income.
+Amounts like 1.234,56 need --decimal-comma.
+
+Nothing is stored if a single row fails, use --dry to preview the import.`,
+	Example: `acc import csv export.csv
+acc import csv bank.csv --map "date=Posting Date,amount=Amount,desc=Memo" --account checking --dry
+acc import csv kontoauszug.csv --map "date=Buchungstag,amount=Betrag" --decimal-comma`,
+	Args: cobra.ExactArgs(1),
+	Run:  ImportCsv,
+}
+
+var importOfxCmd = &cobra.Command{
+	Use:     "ofx <file>",
+	Aliases: []string{"qfx"},
+	Short:   "Import a bank statement in OFX/QFX format",
+	Long: `Import a bank statement in OFX/QFX format (1.x SGML or 2.x XML).
+
+Negative amounts are imported as expenses, positive ones as income. The id the bank
+assigns to every transaction (FITID) is stored, so importing overlapping statements
+into the same account skips transactions that were imported before.`,
+	Example: `acc import ofx statement.ofx --account checking`,
+	Args:    cobra.ExactArgs(1),
+	Run:     ImportOfx,
+}
+
+var importQifCmd = &cobra.Command{
+	Use:   "qif <file>",
+	Short: "Import transactions from a QIF file",
+	Long: `Import the bank, credit card and cash transactions of a QIF file.
+
+Records in an !Account block are booked on that account, all others on --account.
+Payees become descriptions and memos notes. Categories like Food:Groceries become "Food > Groceries", [Account] categories become
+transfers. Exports of several accounts have both legs of a transfer, they are imported
+as a single transfer. Month or day first dates are detected from the file, amounts like 1.234,56
+need --decimal-comma.`,
+	Example: `acc import qif export.qif --account checking`,
+	Args:    cobra.ExactArgs(1),
+	Run:     ImportQif,
+}
+
+var importLedgerCmd = &cobra.Command{
+	Use:     "ledger <file>",
+	Aliases: []string{"hledger"},
+	Short:   "Import a ledger-cli or hledger journal",
+	Long: `Import a ledger-cli or hledger journal.
+
+Every transaction needs exactly two postings: an asset (or liability) account and an
+income or expense account, or two asset accounts for a transfer. Assets:Checking becomes
+the acc account "checking", Expenses:Food:Groceries the category "Food > Groceries".
+Use --journal-account to map names that differ.
+
+Transactions that cannot be represented are listed and nothing is imported, unless
+--skip-unsupported is given.`,
+	Example: `acc import ledger books.journal --dry
+acc import ledger books.journal --journal-account checking=Assets:Bank:Checking --skip-unsupported`,
+	Args: cobra.ExactArgs(1),
+	Run:  ImportJournal,
+}
+
+var importBeancountCmd = &cobra.Command{
+	Use:   "beancount <file>",
+	Short: "Import a beancount journal",
+	Long: `Import a beancount journal.
+
+Accounts and categories are mapped like for "acc import ledger", see its help.`,
+	Example: `acc import beancount books.beancount --dry`,
+	Args:    cobra.ExactArgs(1),
+	Run:     ImportJournal,
+}
+
+func init() {
+	RootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importCsvCmd, importOfxCmd, importQifCmd, importLedgerCmd, importBeancountCmd)
+	for _, c := range []*cobra.Command{importLedgerCmd, importBeancountCmd} {
+		addJournalFlags(c)
+		c.Flags().String("liabilities-account", list.DefaultJournalConfig.Liabilities, "journal account read like an asset account (credit cards, loans)")
+		c.Flags().String("account", "", "account for postings to the assets account itself (default: default)")
+		c.Flags().Bool("skip-unsupported", false, "import the other transactions when some cannot be represented")
+	}
+	importCsvCmd.Flags().String("map", "", "map fields to csv columns (e.g. date=Posting Date,amount=Amount,desc=Memo)")
+	importCsvCmd.Flags().String("account", "", "account for rows without one (default: default)")
+	importCsvCmd.Flags().Bool("decimal-comma", false, "amounts are written like 1.234,56")
+	importOfxCmd.Flags().String("account", "", "account the statement belongs to (default: default)")
+	importQifCmd.Flags().String("account", "", "account for records outside an !Account block (default: default)")
+	importQifCmd.Flags().Bool("decimal-comma", false, "amounts are written like 1.234,56")
+}
+
+func ImportCsv(cmd *cobra.Command, args []string) {
+	mapping, err := importer.ParseMapping(cmd.Flag("map").Value.String())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
+	decimalComma, _ := cmd.Flags().GetBool("decimal-comma")
+	transactions, err := importer.ReadCSV(f, mapping, decimalComma)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	importTransactions(cmd, transactions, false)
+}
+
+func ImportQif(cmd *cobra.Command, args []string) {
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
+	decimalComma, _ := cmd.Flags().GetBool("decimal-comma")
+	transactions, err := importer.ReadQIF(f, decimalComma)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// the records of an account export leave out the account they were exported from
+	importTransactions(cmd, transactions, true)
+}
+
+// ImportJournal imports ledger, hledger and beancount files, the format is the command name
+func ImportJournal(cmd *cobra.Command, args []string) {
+	config, err := journalConfig(cmd)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
+	transactions, unsupported, err := importer.ReadJournal(f, cmd.Name(), config)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, err := range unsupported {
+		fmt.Println("unsupported transaction at", err)
+	}
+	if len(unsupported) > 0 && cmd.Flag("skip-unsupported").Value.String() != "true" {
+		fmt.Printf("Nothing imported, %d transactions cannot be represented in acc. Use --skip-unsupported to import the others\n", len(unsupported))
+		return
+	}
+	importTransactions(cmd, transactions, false)
+}
+
+func ImportOfx(cmd *cobra.Command, args []string) {
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer f.Close()
+	transactions, err := importer.ReadOFX(f)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	importTransactions(cmd, transactions, false)
+}
+
+// importTransactions resolves accounts and stores all transactions at once, or only
+// previews them with --dry. allowMissingDestination books transfers without a destination
+// into --account
+func importTransactions(cmd *cobra.Command, transactions []database.Transaction, allowMissingDestination bool) {
+	if len(transactions) == 0 {
+		fmt.Println("Nothing to import")
+		return
+	}
+	if err := resolveImportAccounts(cmd, transactions, allowMissingDestination); err != nil {
+		fmt.Println(err)
+		return
+	}
+	base, err := database.NewFxRepository().GetBaseCurrency()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	for i := range transactions {
+		if transactions[i].Currency == "" {
+			transactions[i].Currency = base
+		}
+		if err := list.ValidateCurrency(transactions[i].Currency); err != nil {
+			fmt.Printf("transaction %d (%s): %v\n", i+1, transactions[i].Description, err)
+			return
+		}
+		if err := list.ValidateTags(transactions[i].Tags, false); err != nil {
+			fmt.Printf("transaction %d (%s): %v\n", i+1, transactions[i].Description, err)
+			return
+		}
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		list.PreviewRenderer(transactions)
+		return
+	}
+	path, err := autoBackup("import")
+	if err != nil {
+		fmt.Println("failed to back up the database, nothing was imported:", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Backup saved to", path)
+	inserted, err := database.NewTransactionRepository().CreateTransactions(transactions)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Imported %d transactions, skipped %d already imported\n", inserted, len(transactions)-inserted)
+}
+
+// resolveImportAccounts looks up account names, a missing account is the one given with
+// --account. Transfers need a destination unless allowMissingDestination is set, which
+// makes it --account too.
+func resolveImportAccounts(cmd *cobra.Command, transactions []database.Transaction, allowMissingDestination bool) error {
+	accounts, err := database.NewAccountRepository().GetAccounts()
+	if err != nil {
+		return err
+	}
+	// names are matched exactly first, journals usually capitalize them
+	find := func(name string) (int, error) {
+		for _, account := range accounts {
+			if account.Name == name {
+				return account.ID, nil
+			}
+		}
+		for _, account := range accounts {
+			if strings.EqualFold(account.Name, name) {
+				return account.ID, nil
+			}
+		}
+		return 0, fmt.Errorf("account %q not found", name)
+	}
+	fallback := cmd.Flag("account").Value.String()
+	fallbackID := database.DefaultAccountID
+	if fallback != "" {
+		id, err := find(fallback)
+		if err != nil {
+			return err
+		}
+		fallbackID = id
+	}
+	resolve := func(name *string) (int, error) {
+		if *name == "" {
+			*name = fallback
+			return fallbackID, nil
+		}
+		return find(*name)
+	}
+	for i := range transactions {
+		t := &transactions[i]
+		id, err := resolve(&t.Account)
+		if err != nil {
+			return fmt.Errorf("transaction %d (%s): %w", i+1, t.Description, err)
+		}
+		t.AccountID = id
+		if t.Type != "transfer" {
+			t.ToAccount = ""
+			continue
+		}
+		if t.ToAccount == "" && !allowMissingDestination {
+			return fmt.Errorf("transaction %d (%s): transfers need a destination account", i+1, t.Description)
+		}
+		toID, err := resolve(&t.ToAccount)
+		if err != nil {
+			return fmt.Errorf("transaction %d (%s): %w", i+1, t.Description, err)
+		}
+		if toID == id {
+			return fmt.Errorf("transaction %d (%s): cannot transfer to the same account", i+1, t.Description)
+		}
+		t.ToAccountID = &toID
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Package importer reads transactions exported by other tools (or by acc itself)
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/itlightning/dateparse"
+)
+
+// Mapping maps a transaction field to the csv column holding it
+type Mapping map[string]string
+
+// DefaultMapping matches the header written by `acc list -f csv`
+var DefaultMapping = Mapping{
+	"type":     "Type",
+	"amount":   "Amt",
+	"currency": "Currency",
+	"desc":     "Desc",
+	"note":     "Note",
+	"category": "Category",
+	"tags":     "Tags",
+	"account":  "Account",
+	"to":       "ToAccount",
+	"date":     "Date",
+}
+
+// ParseMapping reads "date=Posting Date,amount=Amount", fields that are not given keep their
+// default column
+func ParseMapping(s string) (Mapping, error) {
+	m := Mapping{}
+	for field, column := range DefaultMapping {
+		m[field] = column
+	}
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		column = strings.TrimSpace(column)
+		if !ok || column == "" {
+			return nil, fmt.Errorf("invalid mapping %q, expected field=column", pair)
+		}
+		if _, known := DefaultMapping[field]; !known {
+			return nil, fmt.Errorf("unknown field %q, expected one of date, amount, desc, note, type, category, tags, account, to, currency", field)
+		}
+		m[field] = column
+	}
+	return m, nil
+}
+
+// ParseAmount reads an amount with an optional thousands separator, "1,234.56" or with
+// decimalComma "1.234,56". A separator outside of thousands groups makes the amount
+// invalid, so "3,50" is rejected instead of read as 350.
+func ParseAmount(s string, decimalComma bool) (money.Money, error) {
+	decimal, group := ".", ","
+	if decimalComma {
+		decimal, group = ",", "."
+	}
+	invalid := fmt.Errorf("invalid amount %q", s)
+	if !decimalComma && strings.Contains(s, ",") {
+		invalid = fmt.Errorf("invalid amount %q, use --decimal-comma for amounts like 3,50", s)
+	}
+	whole, fraction, _ := strings.Cut(s, decimal)
+	if strings.Contains(fraction, group) {
+		return 0, invalid
+	}
+	if strings.Contains(whole, group) {
+		groups := strings.Split(strings.TrimLeft(whole, "+-"), group)
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return 0, invalid
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return 0, invalid
+			}
+		}
+		whole = strings.ReplaceAll(whole, group, "")
+	}
+	raw := whole
+	if strings.Contains(s, decimal) {
+		raw += "." + fraction
+	}
+	amount, err := money.Parse(raw)
+	if err != nil {
+		return 0, invalid
+	}
+	return amount, nil
+}
+
+// ReadCSV parses a csv file with a header row. Without a type column, or when it is empty,
+// negative amounts become expenses and positive ones income. Amounts are written like
+// 1,234.56, or like 1.234,56 with decimalComma. Whether dates are month or day first is
+// detected from all dates in the file, like for QIF.
+func ReadCSV(r io.Reader, m Mapping, decimalComma bool) ([]database.Transaction, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		// spreadsheets like to start utf-8 files with a byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	index := map[string]int{}
+	for field, column := range m {
+		if i, ok := columns[strings.ToLower(column)]; ok {
+			index[field] = i
+		}
+	}
+	for _, field := range []string{"date", "amount"} {
+		if _, ok := index[field]; !ok {
+			return nil, fmt.Errorf("column %q not found, map it with --map %s=<column>", m[field], field)
+		}
+	}
+	var rows []func(field string) string
+	var dates []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		get := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		rows = append(rows, get)
+		dates = append(dates, get("date"))
+	}
+	dayFirst := detectDayFirst(dates)
+	var transactions []database.Transaction
+	for i, get := range rows {
+		transaction, err := parseRecord(get, decimalComma, dayFirst)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+func parseRecord(get func(field string) string, decimalComma bool, dayFirst bool) (database.Transaction, error) {
+	var t database.Transaction
+	amount, err := ParseAmount(get("amount"), decimalComma)
+	if err != nil {
+		return t, err
+	}
+	t.Type = strings.ToLower(get("type"))
+	switch t.Type {
+	case "":
+		t.Type = "income"
+		if amount < 0 {
+			t.Type = "expense"
+		}
+	case "income", "expense", "transfer":
+	default:
+		return t, fmt.Errorf("invalid type %q", get("type"))
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	t.Amount = amount
+	// dateparse directly, utils.ParseDate would take the time in our own export for a range
+	date, err := dateparse.ParseAny(get("date"), dateparse.PreferMonthFirst(!dayFirst))
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q", get("date"))
+	}
+	t.OccurredAt = date.Format(utils.DBTimeFormat)
+	t.Description = get("desc")
+	t.Note = get("note")
+	t.Category = get("category")
+	t.Currency = strings.ToUpper(get("currency"))
+	t.Account = get("account")
+	t.ToAccount = get("to")
+	for _, tag := range strings.Split(get("tags"), ",") {
+		if tag = database.NormalizeTag(tag); tag != "" {
+			t.Tags = append(t.Tags, tag)
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,143 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/importer"
+)
+
+func TestParseMapping(t *testing.T) {
+	m, err := importer.ParseMapping("date=Posting Date, amount=Amount,desc=Memo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m["date"] != "Posting Date" || m["amount"] != "Amount" || m["desc"] != "Memo" {
+		t.Error("unexpected mapping", m)
+	}
+	if m["category"] != "Category" {
+		t.Error("expected default column for category, got", m["category"])
+	}
+	for _, invalid := range []string{"date", "foo=bar", "date="} {
+		if _, err := importer.ParseMapping(invalid); err == nil {
+			t.Error("expected error for", invalid)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	m, _ := importer.ParseMapping("date=Posting Date,amount=Amount,desc=Memo")
+	input := "Posting Date,Memo,Amount\n" +
+		"2024-01-05,Coffee,-3.50\n" +
+		"01/31/2024,Salary,\"2,500.00\"\n"
+	transactions, err := importer.ReadCSV(strings.NewReader(input), m, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 {
+		t.Fatal("expected 2 transactions, got", len(transactions))
+	}
+	coffee, salary := transactions[0], transactions[1]
+	if coffee.Type != "expense" || coffee.Amount != 350 || coffee.Description != "Coffee" {
+		t.Error("unexpected transaction", coffee)
+	}
+	if coffee.OccurredAt != "2024-01-05 00:00:00" {
+		t.Error("expected 2024-01-05 00:00:00, got", coffee.OccurredAt)
+	}
+	if salary.Type != "income" || salary.Amount != 250000 {
+		t.Error("unexpected transaction", salary)
+	}
+	if salary.OccurredAt != "2024-01-31 00:00:00" {
+		t.Error("expected 2024-01-31 00:00:00, got", salary.OccurredAt)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []string{
+		"Desc,Amt\ncoffee,1\n",
+		"Date,Amt\n2024-01-05,abc\n",
+		"Date,Amt\nnot a date,1\n",
+		"Date,Amt,Type\n2024-01-05,1,refund\n",
+	}
+	for _, input := range tests {
+		if _, err := importer.ReadCSV(strings.NewReader(input), importer.DefaultMapping, false); err == nil {
+			t.Error("expected error for", input)
+		}
+	}
+}
+
+func TestReadCSVDecimalComma(t *testing.T) {
+	m, _ := importer.ParseMapping("date=Buchungstag,amount=Betrag,desc=Verwendungszweck")
+	input := "Buchungstag,Verwendungszweck,Betrag\n" +
+		"05.01.2024,Kaffee,\"-3,50\"\n" +
+		"31.01.2024,Gehalt,\"1.234,56\"\n" +
+		"01.02.2024,Miete,-800\n"
+	if _, err := importer.ReadCSV(strings.NewReader(input), m, false); err == nil {
+		t.Error("expected decimal commas to be rejected without --decimal-comma")
+	}
+	transactions, err := importer.ReadCSV(strings.NewReader(input), m, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 3 {
+		t.Fatal("expected 3 transactions, got", len(transactions))
+	}
+	for i, want := range []int64{350, 123456, 80000} {
+		if int64(transactions[i].Amount) != want {
+			t.Errorf("expected %d, got %d", want, transactions[i].Amount)
+		}
+	}
+	if transactions[0].OccurredAt != "2024-01-05 00:00:00" {
+		t.Error("expected day first dates, got", transactions[0].OccurredAt)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input        string
+		decimalComma bool
+		want         int64
+		valid        bool
+	}{
+		{"1,234.56", false, 123456, true},
+		{"-1,234,567", false, -123456700, true},
+		{"3.50", false, 350, true},
+		{"3,50", false, 0, false},
+		{"1.234,56", false, 0, false},
+		{"12,34.5", false, 0, false},
+		{"1.234,56", true, 123456, true},
+		{"-3,5", true, -350, true},
+		{"1.234", true, 123400, true},
+		{"3.50", true, 0, false},
+		{"1,234.56", true, 0, false},
+	}
+	for _, test := range tests {
+		amount, err := importer.ParseAmount(test.input, test.decimalComma)
+		if (err == nil) != test.valid || (test.valid && int64(amount) != test.want) {
+			t.Errorf("%q (decimal comma %v): expected %d valid %v, got %d %v", test.input, test.decimalComma, test.want, test.valid, amount, err)
+		}
+	}
+}
+
+func TestReadCSVExport(t *testing.T) {
+	input := "ID,Type,Amt,Currency,BaseAmt,Desc,Category,Tags,Account,ToAccount,Date,CreatedAt,UpdatedAt\n" +
+		"1,transfer,50.00,USD,50.00,savings,,\"monthly,#auto\",default,savings,2024-01-17T08:19:12Z,2024-01-17T08:19:12Z,2024-01-17T08:19:12Z\n" +
+		"2,expense,4.50,EUR,,coffee,Food > Cafe,,default,,2024-01-18T10:00:00Z,2024-01-18T10:00:00Z,2024-01-18T10:00:00Z\n"
+	transactions, err := importer.ReadCSV(strings.NewReader(input), importer.DefaultMapping, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer, coffee := transactions[0], transactions[1]
+	if transfer.Type != "transfer" || transfer.Account != "default" || transfer.ToAccount != "savings" {
+		t.Error("unexpected transfer", transfer)
+	}
+	if len(transfer.Tags) != 2 || transfer.Tags[0] != "monthly" || transfer.Tags[1] != "auto" {
+		t.Error("expected tags monthly, auto, got", transfer.Tags)
+	}
+	if transfer.OccurredAt != "2024-01-17 08:19:12" {
+		t.Error("expected 2024-01-17 08:19:12, got", transfer.OccurredAt)
+	}
+	if coffee.Category != "Food > Cafe" || coffee.Currency != "EUR" || coffee.Amount != 450 {
+		t.Error("unexpected transaction", coffee)
+	}
+}
@@ -0,0 +1,470 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+)
+
+// commodity symbols ledger files commonly use instead of currency codes
+var commoditySymbols = map[string]string{
+	"$": "USD",
+	"€": "EUR",
+	"£": "GBP",
+	"¥": "JPY",
+	"₹": "INR",
+}
+
+// beancount directives other than transactions, they are skipped
+var beancountDirectives = map[string]bool{
+	"open": true, "close": true, "balance": true, "pad": true, "price": true, "note": true,
+	"document": true, "event": true, "commodity": true, "custom": true, "query": true,
+}
+
+var (
+	journalAmountPattern = regexp.MustCompile(`^(-)?\s*([^-\d\s.,][^\d\s-]*)?\s*(-)?\s*(\d[\d,]*(?:\.\d+)?|\.\d+)\s*(\S*)$`)
+	ledgerTagsPattern    = regexp.MustCompile(`(?:^|\s):((?:[^:\s]+:)+)`)
+	hledgerTagPattern    = regexp.MustCompile(`(?:^|[\s,])([\p{L}\p{N}_.-]+):(?:\s|,|$)`)
+	beancountMetaPattern = regexp.MustCompile(`^[a-z][a-zA-Z0-9_-]*:(\s|$)`)
+)
+
+type journalPosting struct {
+	account   string
+	amount    *money.Money
+	commodity string
+	// the posting converts between commodities (@ price or {cost})
+	priced bool
+}
+
+type journalEntry struct {
+	line        int
+	date        time.Time
+	description string
+	tags        []string
+	postings    []journalPosting
+}
+
+// ReadJournal parses a ledger/hledger ("ledger") or beancount ("beancount") journal and
+// turns every two posting transaction into an acc transaction. Transactions that cannot be
+// represented (more postings, currency conversions, postings between two expense
+// accounts, ...) are returned as unsupported, syntax errors as err.
+func ReadJournal(r io.Reader, format string, config database.JournalConfig) (transactions []database.Transaction, unsupported []error, err error) {
+	var entries []journalEntry
+	if format == "beancount" {
+		entries, err = parseBeancount(r)
+	} else {
+		entries, err = parseLedger(r)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		t, err := collapseJournalEntry(entry, config)
+		if err != nil {
+			unsupported = append(unsupported, fmt.Errorf("line %d (%s): %w", entry.line, entry.description, err))
+			continue
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, unsupported, nil
+}
+
+func parseLedger(r io.Reader) ([]journalEntry, error) {
+	var entries []journalEntry
+	var current *journalEntry
+	finish := func() {
+		if current != nil {
+			entries = append(entries, *current)
+			current = nil
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if text == "" {
+			finish()
+			continue
+		}
+		if text[0] != ' ' && text[0] != '\t' {
+			finish()
+			// everything but a transaction (comments, account, commodity, P, include, periodic
+			// ~ and automated = transactions) starts with something else than a date
+			if text[0] < '0' || text[0] > '9' {
+				continue
+			}
+			entry, err := parseLedgerHeader(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			entry.line = line
+			current = &entry
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		text = strings.TrimSpace(text)
+		text, comment, _ := strings.Cut(text, ";")
+		current.tags = append(current.tags, commentTags(comment)...)
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		posting, skip, err := parseLedgerPosting(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if !skip {
+			current.postings = append(current.postings, posting)
+		}
+	}
+	finish()
+	return entries, scanner.Err()
+}
+
+// parseLedgerHeader reads DATE[=DATE2] [*|!] [(CODE)] DESCRIPTION [; COMMENT]
+func parseLedgerHeader(text string) (journalEntry, error) {
+	var entry journalEntry
+	dateField, rest, _ := strings.Cut(text, " ")
+	dateField, _, _ = strings.Cut(dateField, "=")
+	date, err := parseJournalDate(dateField)
+	if err != nil {
+		return entry, err
+	}
+	entry.date = date
+	rest, comment, _ := strings.Cut(rest, ";")
+	entry.tags = commentTags(comment)
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "*") || strings.HasPrefix(rest, "!") {
+		rest = strings.TrimSpace(rest[1:])
+	}
+	if strings.HasPrefix(rest, "(") {
+		if end := strings.Index(rest, ")"); end >= 0 {
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+	}
+	entry.description = rest
+	return entry, nil
+}
+
+// parseLedgerPosting reads [*|!] ACCOUNT  [AMOUNT] [@ PRICE] [= ASSERTION], unbalanced
+// virtual postings in parentheses are skipped
+func parseLedgerPosting(text string) (journalPosting, bool, error) {
+	var posting journalPosting
+	if strings.HasPrefix(text, "* ") || strings.HasPrefix(text, "! ") {
+		text = strings.TrimSpace(text[2:])
+	}
+	account, amount := text, ""
+	if i := strings.Index(text, "  "); i >= 0 {
+		account, amount = text[:i], strings.TrimSpace(text[i:])
+	}
+	if i := strings.Index(account, "\t"); i >= 0 {
+		account, amount = account[:i], strings.TrimSpace(account[i:]+" "+amount)
+	}
+	if strings.HasPrefix(account, "(") && strings.HasSuffix(account, ")") {
+		return posting, true, nil
+	}
+	account = strings.TrimSuffix(strings.TrimPrefix(account, "["), "]")
+	posting.account = account
+	amount, _, _ = strings.Cut(amount, "=")
+	if strings.Contains(amount, "@") {
+		posting.priced = true
+		amount, _, _ = strings.Cut(amount, "@")
+	}
+	return posting, false, parseJournalAmount(strings.TrimSpace(amount), &posting)
+}
+
+func parseBeancount(r io.Reader) ([]journalEntry, error) {
+	var entries []journalEntry
+	var current *journalEntry
+	finish := func() {
+		if current != nil {
+			entries = append(entries, *current)
+			current = nil
+		}
+	}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		if text == "" {
+			finish()
+			continue
+		}
+		if text[0] != ' ' && text[0] != '\t' {
+			finish()
+			// option, plugin, include, pushtag, org mode headings and comments
+			if text[0] < '0' || text[0] > '9' {
+				continue
+			}
+			entry, skip, err := parseBeancountHeader(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if !skip {
+				entry.line = line
+				current = &entry
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		text = strings.TrimSpace(text)
+		text, _, _ = strings.Cut(text, ";")
+		text = strings.TrimSpace(text)
+		if text == "" || beancountMetaPattern.MatchString(text) {
+			continue
+		}
+		posting, err := parseBeancountPosting(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		current.postings = append(current.postings, posting)
+	}
+	finish()
+	return entries, scanner.Err()
+}
+
+// parseBeancountHeader reads DATE [*|!|txn] ["PAYEE"] "NARRATION" [#tag] [^link], other
+// directives are skipped
+func parseBeancountHeader(text string) (journalEntry, bool, error) {
+	var entry journalEntry
+	dateField, rest, _ := strings.Cut(text, " ")
+	date, err := parseJournalDate(dateField)
+	if err != nil {
+		return entry, false, err
+	}
+	entry.date = date
+	rest, _, _ = strings.Cut(rest, ";")
+	rest = strings.TrimSpace(rest)
+	flag, _, _ := strings.Cut(rest, " ")
+	if beancountDirectives[flag] {
+		return entry, true, nil
+	}
+	if flag != "*" && flag != "!" && flag != "txn" {
+		return entry, false, fmt.Errorf("unknown directive %q", flag)
+	}
+	rest = strings.TrimSpace(rest[len(flag):])
+	var texts []string
+	for rest != "" {
+		switch {
+		case rest[0] == '"':
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return entry, false, fmt.Errorf("invalid string %s", rest)
+			}
+			s, _ := strconv.Unquote(quoted)
+			texts = append(texts, s)
+			rest = strings.TrimSpace(rest[len(quoted):])
+		default:
+			token, remaining, _ := strings.Cut(rest, " ")
+			if strings.HasPrefix(token, "#") {
+				entry.tags = append(entry.tags, token[1:])
+			}
+			rest = strings.TrimSpace(remaining)
+		}
+	}
+	var description []string
+	for _, s := range texts {
+		if s != "" {
+			description = append(description, s)
+		}
+	}
+	entry.description = strings.Join(description, " - ")
+	return entry, false, nil
+}
+
+// parseBeancountPosting reads [FLAG] ACCOUNT [AMOUNT CURRENCY] [{COST}] [@ PRICE]
+func parseBeancountPosting(text string) (journalPosting, error) {
+	var posting journalPosting
+	if strings.HasPrefix(text, "* ") || strings.HasPrefix(text, "! ") {
+		text = strings.TrimSpace(text[2:])
+	}
+	account, amount, _ := strings.Cut(text, " ")
+	posting.account = account
+	if strings.ContainsAny(amount, "{@") {
+		posting.priced = true
+		amount = amount[:strings.IndexAny(amount, "{@")]
+	}
+	return posting, parseJournalAmount(strings.TrimSpace(amount), &posting)
+}
+
+// parseJournalAmount reads amounts like 12.50 USD, USD 12.50, -$12.50 or $-1,234.50, an
+// empty amount is left for the other posting to balance
+func parseJournalAmount(s string, posting *journalPosting) error {
+	if s == "" {
+		return nil
+	}
+	m := journalAmountPattern.FindStringSubmatch(s)
+	if m == nil {
+		return fmt.Errorf("invalid amount %q", s)
+	}
+	amount, err := money.Parse(strings.ReplaceAll(m[4], ",", ""))
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", s)
+	}
+	if m[1] != "" || m[3] != "" {
+		amount = -amount
+	}
+	commodity := strings.Trim(m[2]+m[5], `"`)
+	if code, ok := commoditySymbols[commodity]; ok {
+		commodity = code
+	}
+	posting.amount = &amount
+	posting.commodity = commodity
+	return nil
+}
+
+func parseJournalDate(s string) (time.Time, error) {
+	s = strings.NewReplacer("/", "-", ".", "-").Replace(s)
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		// ledger allows single digit months and days
+		date, err = time.Parse("2006-1-2", s)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", s)
+	}
+	return date, nil
+}
+
+// commentTags reads ledger (:tag1:tag2:) and hledger (tag1:, tag2: value) tags from a comment
+func commentTags(comment string) []string {
+	var tags []string
+	for _, m := range ledgerTagsPattern.FindAllStringSubmatch(comment, -1) {
+		tags = append(tags, strings.Split(strings.TrimSuffix(m[1], ":"), ":")...)
+	}
+	comment = ledgerTagsPattern.ReplaceAllString(comment, " ")
+	for _, m := range hledgerTagPattern.FindAllStringSubmatch(comment, -1) {
+		tags = append(tags, m[1])
+	}
+	return tags
+}
+
+// journalAccountKind tells whether a journal account is an asset (or liability), income or
+// expense account and returns the acc account name or category path it maps to
+func journalAccountKind(account string, config database.JournalConfig) (string, string) {
+	below := func(root string) (string, bool) {
+		if root == "" {
+			return "", false
+		}
+		if account == root {
+			return "", true
+		}
+		rest, ok := strings.CutPrefix(account, root+":")
+		return rest, ok
+	}
+	kind, rest := "", ""
+	for _, k := range []struct{ kind, root string }{
+		{"asset", config.Assets},
+		{"asset", config.Liabilities},
+		{"income", config.Income},
+		{"expense", config.Expenses},
+	} {
+		if r, ok := below(k.root); ok {
+			kind, rest = k.kind, r
+			break
+		}
+	}
+	if kind == "" {
+		return "", ""
+	}
+	// explicit mappings win, the longest mapped parent of the account is used
+	mappedName, mappedLen := "", -1
+	for name, mapped := range config.Accounts {
+		if (account == mapped || strings.HasPrefix(account, mapped+":")) && len(mapped) > mappedLen {
+			mappedName, mappedLen = name, len(mapped)
+		}
+	}
+	segments := []string{}
+	if mappedLen >= 0 {
+		if mappedName != "" {
+			segments = append(segments, mappedName)
+		}
+		if len(account) > mappedLen {
+			segments = append(segments, strings.Split(account[mappedLen+1:], ":")...)
+		}
+	} else if rest != "" {
+		segments = strings.Split(rest, ":")
+	}
+	if kind == "asset" {
+		return kind, strings.Join(segments, ":")
+	}
+	if len(segments) == 1 && segments[0] == "Uncategorized" {
+		segments = nil
+	}
+	return kind, strings.Join(segments, database.CategorySeparator)
+}
+
+func collapseJournalEntry(entry journalEntry, config database.JournalConfig) (database.Transaction, error) {
+	t := database.Transaction{
+		Description: entry.description,
+		OccurredAt:  entry.date.Format(utils.DBTimeFormat),
+		Tags:        entry.tags,
+	}
+	if len(entry.postings) != 2 {
+		return t, fmt.Errorf("%d postings, only transactions with 2 postings are supported", len(entry.postings))
+	}
+	a, b := entry.postings[0], entry.postings[1]
+	if a.priced || b.priced {
+		return t, errors.New("currency conversions are not supported")
+	}
+	switch {
+	case a.amount == nil && b.amount == nil:
+		return t, errors.New("both postings are missing an amount")
+	case a.amount == nil:
+		amount := -*b.amount
+		a.amount, a.commodity = &amount, b.commodity
+	case b.amount == nil:
+		amount := -*a.amount
+		b.amount, b.commodity = &amount, a.commodity
+	}
+	if a.commodity != b.commodity || *a.amount != -*b.amount {
+		return t, errors.New("postings do not balance")
+	}
+	t.Currency = strings.ToUpper(a.commodity)
+	kindA, nameA := journalAccountKind(a.account, config)
+	kindB, nameB := journalAccountKind(b.account, config)
+	// the asset posting goes first
+	if kindB == "asset" && kindA != "asset" {
+		a, b = b, a
+		kindA, kindB = kindB, kindA
+		nameA, nameB = nameB, nameA
+	}
+	if kindA != "asset" || kindB == "" {
+		return t, fmt.Errorf("cannot map %s and %s to an income, expense or transfer", a.account, b.account)
+	}
+	amount := *b.amount
+	switch kindB {
+	case "asset":
+		if nameA == nameB {
+			return t, fmt.Errorf("transfer from %s to itself", a.account)
+		}
+		t.Type = "transfer"
+		t.Account, t.ToAccount = nameA, nameB
+		if amount < 0 {
+			t.Account, t.ToAccount = nameB, nameA
+		}
+	case "expense", "income":
+		// money flowing into an expense account is an expense, refunds and income flow out
+		t.Type = "expense"
+		if amount < 0 {
+			t.Type = "income"
+		}
+		t.Account = nameA
+		t.Category = nameB
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	t.Amount = amount
+	return t, nil
+}
@@ -0,0 +1,169 @@
+package importer_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/importer"
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+const ledgerJournal = `; opening comment
+account Assets:Checking
+commodity $
+
+2024/01/05 * (1001) Grocery Store  ; :weekly:food:
+    Expenses:Food:Groceries    $12.50
+    Assets:Checking
+
+2024-01-31 Salary
+    ; payroll:, month: january
+    Assets:Checking            2,500.00 USD
+    Income:Salary
+
+2024-02-01 Savings
+    Assets:Checking           -100 USD
+    Assets:Savings
+
+2024-02-02 Card payment
+    Liabilities:Visa            50 USD
+    Assets:Checking            -50 USD
+
+2024-02-03 Refund
+    Expenses:Food             -$5
+    Assets:Checking
+
+2024-02-04 Split
+    Expenses:Food              $5
+    Expenses:Home              $5
+    Assets:Checking
+
+2024-02-05 Exchange
+    Assets:Checking            100 EUR @ 1.10 USD
+    Assets:Savings            -110 USD
+
+2024-02-06 Opening balance
+    Assets:Checking            1000 USD
+    Equity:Opening
+`
+
+func TestReadLedger(t *testing.T) {
+	transactions, unsupported, err := importer.ReadJournal(strings.NewReader(ledgerJournal), "ledger", list.DefaultJournalConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unsupported) != 3 {
+		t.Error("expected 3 unsupported transactions, got", unsupported)
+	}
+	if len(transactions) != 5 {
+		t.Fatal("expected 5 transactions, got", len(transactions))
+	}
+	groceries := transactions[0]
+	if groceries.Type != "expense" || groceries.Amount != 1250 || groceries.Currency != "USD" || groceries.Account != "Checking" {
+		t.Error("unexpected transaction", groceries)
+	}
+	if groceries.Description != "Grocery Store" || groceries.Category != "Food > Groceries" || groceries.OccurredAt != "2024-01-05 00:00:00" {
+		t.Error("unexpected transaction", groceries)
+	}
+	if strings.Join(groceries.Tags, ",") != "weekly,food" {
+		t.Error("expected tags weekly,food, got", groceries.Tags)
+	}
+	salary := transactions[1]
+	if salary.Type != "income" || salary.Amount != 250000 || salary.Category != "Salary" {
+		t.Error("unexpected transaction", salary)
+	}
+	if strings.Join(salary.Tags, ",") != "payroll,month" {
+		t.Error("expected tags payroll,month, got", salary.Tags)
+	}
+	savings := transactions[2]
+	if savings.Type != "transfer" || savings.Account != "Checking" || savings.ToAccount != "Savings" || savings.Amount != 10000 {
+		t.Error("unexpected transfer", savings)
+	}
+	card := transactions[3]
+	if card.Type != "transfer" || card.Account != "Checking" || card.ToAccount != "Visa" {
+		t.Error("unexpected transfer", card)
+	}
+	refund := transactions[4]
+	if refund.Type != "income" || refund.Amount != 500 || refund.Category != "Food" {
+		t.Error("unexpected refund", refund)
+	}
+}
+
+func TestReadBeancount(t *testing.T) {
+	input := `option "operating_currency" "EUR"
+2024-01-01 open Assets:Checking
+2024-01-01 open Expenses:Food
+
+* Food
+2024-01-05 * "Bakery" "Bread" #breakfast ^receipt-1
+  id: "42"
+  Expenses:Food  3.20 EUR
+    note: "fresh"
+  Assets:Checking
+
+2024-01-06 balance Assets:Checking  -3.20 EUR
+`
+	transactions, unsupported, err := importer.ReadJournal(strings.NewReader(input), "beancount", list.DefaultJournalConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unsupported) != 0 || len(transactions) != 1 {
+		t.Fatal("expected 1 transaction, got", transactions, unsupported)
+	}
+	bread := transactions[0]
+	if bread.Type != "expense" || bread.Amount != 320 || bread.Currency != "EUR" || bread.Description != "Bakery - Bread" {
+		t.Error("unexpected transaction", bread)
+	}
+	if len(bread.Tags) != 1 || bread.Tags[0] != "breakfast" {
+		t.Error("expected tag breakfast, got", bread.Tags)
+	}
+}
+
+func TestReadJournalErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+	}{
+		{"ledger", "2024-13-01 Bad date\n    Expenses:Food  $1\n    Assets:Checking\n"},
+		{"ledger", "2024-01-01 Bad amount\n    Expenses:Food  one dollar\n    Assets:Checking\n"},
+		{"beancount", "2024-01-01 unknown Assets:Checking\n"},
+	}
+	for _, tt := range tests {
+		if _, _, err := importer.ReadJournal(strings.NewReader(tt.input), tt.format, list.DefaultJournalConfig); err == nil {
+			t.Error("expected error for", tt.input)
+		}
+	}
+}
+
+func TestJournalRoundTrip(t *testing.T) {
+	want := []database.Transaction{
+		{Type: "expense", Amount: 1250, Currency: "USD", Description: "Groceries", Category: "Food > Groceries", Account: "Checking", Tags: []string{"weekly"}, OccurredAt: "2024-01-05T00:00:00Z"},
+		{Type: "income", Amount: 250000, Currency: "USD", Description: "Salary", Account: "Checking", OccurredAt: "2024-01-31T00:00:00Z"},
+		{Type: "transfer", Amount: 10000, Currency: "EUR", Description: "Savings", Account: "Checking", ToAccount: "Savings", OccurredAt: "2024-02-01T00:00:00Z"},
+	}
+	config := list.DefaultJournalConfig
+	config.Accounts = map[string]string{"Food": "Expenses:Dining"}
+	for _, format := range []string{"ledger", "hledger", "beancount"} {
+		var b bytes.Buffer
+		if err := list.WriteJournal(&b, want, format, config); err != nil {
+			t.Fatal(err)
+		}
+		got, unsupported, err := importer.ReadJournal(&b, format, config)
+		if err != nil || len(unsupported) > 0 {
+			t.Fatal(format, err, unsupported)
+		}
+		if len(got) != len(want) {
+			t.Fatal(format, "expected", len(want), "transactions, got", len(got))
+		}
+		for i := range want {
+			w, g := want[i], got[i]
+			if g.Type != w.Type || g.Amount != w.Amount || g.Currency != w.Currency || g.Description != w.Description ||
+				g.Category != w.Category || g.Account != w.Account || g.ToAccount != w.ToAccount ||
+				strings.Join(g.Tags, ",") != strings.Join(w.Tags, ",") {
+				t.Errorf("%s transaction %d: expected %+v, got %+v", format, i, w, g)
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+)
+
+// transaction types that are money going out even when the bank leaves out the minus sign
+var ofxDebitTypes = map[string]bool{
+	"DEBIT":       true,
+	"PAYMENT":     true,
+	"CHECK":       true,
+	"FEE":         true,
+	"SRVCHG":      true,
+	"ATM":         true,
+	"POS":         true,
+	"DIRECTDEBIT": true,
+	"CASH":        true,
+}
+
+// ReadOFX parses the STMTTRN entries of an OFX 1.x (SGML) or 2.x (XML) statement, the FITID
+// of every entry becomes its ExternalID
+func ReadOFX(r io.Reader) ([]database.Transaction, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := string(b)
+	start := strings.Index(strings.ToUpper(content), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("not an OFX file, <OFX> not found")
+	}
+	var transactions []database.Transaction
+	var entry map[string]string
+	currency := ""
+	// SGML leaves out the closing tags of leaf elements, so every tag is read as
+	// <NAME>value and closing tags are only used to end a STMTTRN
+	for _, token := range strings.Split(content[start:], "<")[1:] {
+		tag, value, _ := strings.Cut(token, ">")
+		tag = strings.ToUpper(strings.TrimSpace(tag))
+		value = unescapeOFX(strings.TrimSpace(value))
+		switch {
+		case tag == "STMTTRN":
+			entry = map[string]string{}
+		case tag == "/STMTTRN":
+			if entry == nil {
+				continue
+			}
+			t, err := parseOFXEntry(entry, currency)
+			if err != nil {
+				return nil, err
+			}
+			transactions = append(transactions, t)
+			entry = nil
+		case tag == "CURDEF":
+			currency = strings.ToUpper(value)
+		case strings.HasPrefix(tag, "/"):
+		case entry != nil && value != "":
+			if _, ok := entry[tag]; !ok {
+				entry[tag] = value
+			}
+		}
+	}
+	return transactions, nil
+}
+
+func parseOFXEntry(entry map[string]string, currency string) (database.Transaction, error) {
+	var t database.Transaction
+	fitid := entry["FITID"]
+	if fitid == "" {
+		return t, errors.New("transaction without FITID")
+	}
+	raw := entry["TRNAMT"]
+	// some banks write decimal commas
+	if !strings.Contains(raw, ".") {
+		raw = strings.Replace(raw, ",", ".", 1)
+	}
+	amount, err := money.Parse(raw)
+	if err != nil {
+		return t, fmt.Errorf("transaction %s: invalid amount %q", fitid, entry["TRNAMT"])
+	}
+	t.Type = "income"
+	if amount < 0 || ofxDebitTypes[entry["TRNTYPE"]] {
+		t.Type = "expense"
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	t.Amount = amount
+	date, err := parseOFXDate(entry["DTPOSTED"])
+	if err != nil {
+		return t, fmt.Errorf("transaction %s: invalid date %q", fitid, entry["DTPOSTED"])
+	}
+	t.OccurredAt = date.Format(utils.DBTimeFormat)
+	name, memo := entry["NAME"], entry["MEMO"]
+	switch {
+	case name == "":
+		t.Description = memo
+	case memo == "" || memo == name:
+		t.Description = name
+	default:
+		t.Description = name + " - " + memo
+	}
+	t.Currency = currency
+	t.ExternalID = &fitid
+	return t, nil
+}
+
+// parseOFXDate reads YYYYMMDD[HHMMSS[.XXX]][[offset:TZ]], the timezone is ignored so the
+// date stays the one printed on the statement
+func parseOFXDate(s string) (time.Time, error) {
+	if i := strings.IndexAny(s, ".["); i >= 0 {
+		s = s[:i]
+	}
+	switch len(s) {
+	case 8:
+		return time.Parse("20060102", s)
+	case 12:
+		return time.Parse("200601021504", s)
+	case 14:
+		return time.Parse("20060102150405", s)
+	}
+	return time.Time{}, errors.New("invalid date")
+}
+
+var ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+
+func unescapeOFX(s string) string {
+	return ofxEntities.Replace(s)
+}
@@ -0,0 +1,112 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/importer"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>EUR
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240105120000.000[-5:EST]
+<TRNAMT>-12.50
+<FITID>2024010501
+<NAME>GROCERY STORE
+<MEMO>card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240131
+<TRNAMT>2500,00
+<FITID>2024013101
+<NAME>ACME &amp; CO
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlStatement = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CURDEF>USD</CURDEF>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <TRNTYPE>FEE</TRNTYPE>
+        <DTPOSTED>20240201</DTPOSTED>
+        <TRNAMT>5.00</TRNAMT>
+        <FITID>F1</FITID>
+        <MEMO>Annual fee</MEMO>
+      </STMTTRN>
+    </BANKTRANLIST>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestReadOFXSGML(t *testing.T) {
+	transactions, err := importer.ReadOFX(strings.NewReader(sgmlStatement))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 {
+		t.Fatal("expected 2 transactions, got", len(transactions))
+	}
+	groceries, salary := transactions[0], transactions[1]
+	if groceries.Type != "expense" || groceries.Amount != 1250 || groceries.Currency != "EUR" {
+		t.Error("unexpected transaction", groceries)
+	}
+	if groceries.Description != "GROCERY STORE - card 1234" {
+		t.Error("unexpected description", groceries.Description)
+	}
+	if groceries.OccurredAt != "2024-01-05 12:00:00" {
+		t.Error("expected 2024-01-05 12:00:00, got", groceries.OccurredAt)
+	}
+	if groceries.ExternalID == nil || *groceries.ExternalID != "2024010501" {
+		t.Error("expected FITID 2024010501, got", groceries.ExternalID)
+	}
+	if salary.Type != "income" || salary.Amount != 250000 || salary.Description != "ACME & CO" {
+		t.Error("unexpected transaction", salary)
+	}
+}
+
+func TestReadOFXXML(t *testing.T) {
+	transactions, err := importer.ReadOFX(strings.NewReader(xmlStatement))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 1 {
+		t.Fatal("expected 1 transaction, got", len(transactions))
+	}
+	fee := transactions[0]
+	// fees are expenses even without a minus sign
+	if fee.Type != "expense" || fee.Amount != 500 || fee.Description != "Annual fee" || fee.Currency != "USD" {
+		t.Error("unexpected transaction", fee)
+	}
+	if fee.OccurredAt != "2024-02-01 00:00:00" {
+		t.Error("expected 2024-02-01 00:00:00, got", fee.OccurredAt)
+	}
+}
+
+func TestReadOFXErrors(t *testing.T) {
+	tests := []string{
+		"not ofx at all",
+		"<OFX><STMTTRN><TRNAMT>1.00<DTPOSTED>20240101</STMTTRN></OFX>",
+		"<OFX><STMTTRN><FITID>1<TRNAMT>abc<DTPOSTED>20240101</STMTTRN></OFX>",
+		"<OFX><STMTTRN><FITID>1<TRNAMT>1.00<DTPOSTED>2024</STMTTRN></OFX>",
+	}
+	for _, input := range tests {
+		if _, err := importer.ReadOFX(strings.NewReader(input)); err == nil {
+			t.Error("expected error for", input)
+		}
+	}
+}
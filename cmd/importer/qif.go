@@ -0,0 +1,217 @@
+package importer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+)
+
+// QIF sections holding bank transactions, everything else (categories, memorized
+// transactions, investments) is skipped
+var qifSections = map[string]bool{
+	"!TYPE:BANK":  true,
+	"!TYPE:CCARD": true,
+	"!TYPE:CASH":  true,
+}
+
+type qifRecord struct {
+	account string
+	fields  map[byte]string
+}
+
+// ReadQIF parses the !Type:Bank, !Type:CCard and !Type:Cash records of a QIF file. Records
+// after an !Account block belong to that account, the others to the account chosen on
+// import. Whether dates are month or day first is detected from all dates in the file,
+// amounts are read like for ReadCSV.
+func ReadQIF(r io.Reader, decimalComma bool) ([]database.Transaction, error) {
+	var records []qifRecord
+	scanner := bufio.NewScanner(r)
+	section := ""
+	account := ""
+	current := map[byte]string{}
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		line = strings.TrimPrefix(line, "\ufeff")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToUpper(strings.TrimSpace(line))
+			// !Option and !Clear lines only switch Quicken behaviour
+			if !strings.HasPrefix(header, "!OPTION") && !strings.HasPrefix(header, "!CLEAR") {
+				section = header
+			}
+			current = map[byte]string{}
+			continue
+		}
+		if line[0] == '^' {
+			switch {
+			case section == "!ACCOUNT":
+				account = current['N']
+			case qifSections[section] && len(current) > 0:
+				records = append(records, qifRecord{account: account, fields: current})
+			}
+			current = map[byte]string{}
+			continue
+		}
+		// split transactions repeat S/E/$ per split, the record totals are used
+		if _, ok := current[line[0]]; !ok {
+			current[line[0]] = strings.TrimSpace(line[1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	var dates []string
+	for _, record := range records {
+		dates = append(dates, record.fields['D'])
+	}
+	dayFirst := detectDayFirst(dates)
+	parsed := make([]database.Transaction, len(records))
+	// transfers by account, destination, date and amount, counted on the account the money
+	// leaves
+	outflows := map[string]int{}
+	for i, record := range records {
+		t, err := parseQIFRecord(record, dayFirst, decimalComma)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		parsed[i] = t
+		if t.Type == "transfer" && record.account != "" && record.account == t.Account {
+			outflows[qifTransferKey(t)]++
+		}
+	}
+	// exports of several accounts write both legs of a transfer, the leg on the destination
+	// account is dropped when the file has the one on the source account
+	var transactions []database.Transaction
+	for i, t := range parsed {
+		if t.Type == "transfer" && records[i].account != "" && records[i].account == t.ToAccount && outflows[qifTransferKey(t)] > 0 {
+			outflows[qifTransferKey(t)]--
+			continue
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, nil
+}
+
+func qifTransferKey(t database.Transaction) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d", t.Account, t.ToAccount, t.OccurredAt, t.Amount)
+}
+
+func parseQIFRecord(record qifRecord, dayFirst bool, decimalComma bool) (database.Transaction, error) {
+	var t database.Transaction
+	raw := record.fields['T']
+	if raw == "" {
+		raw = record.fields['U']
+	}
+	amount, err := ParseAmount(raw, decimalComma)
+	if err != nil {
+		return t, err
+	}
+	date, err := parseQIFDate(record.fields['D'], dayFirst)
+	if err != nil {
+		return t, fmt.Errorf("invalid date %q", record.fields['D'])
+	}
+	t.OccurredAt = date.Format(utils.DBTimeFormat)
+	// the payee is the description and the memo the note, a memo without a payee describes it
+	payee, memo := record.fields['P'], record.fields['M']
+	switch {
+	case payee == "":
+		t.Description = memo
+	case memo == payee:
+		t.Description = payee
+	default:
+		t.Description, t.Note = payee, memo
+	}
+	t.Type = "income"
+	if amount < 0 {
+		t.Type = "expense"
+		amount = -amount
+	}
+	t.Amount = amount
+	category := record.fields['L']
+	// [Account] is a transfer, money leaves the record's account when the amount is negative
+	if strings.HasPrefix(category, "[") && strings.HasSuffix(category, "]") {
+		other := strings.TrimSpace(category[1 : len(category)-1])
+		if t.Type == "expense" {
+			t.Account, t.ToAccount = record.account, other
+		} else {
+			t.Account, t.ToAccount = other, record.account
+		}
+		t.Type = "transfer"
+		return t, nil
+	}
+	t.Account = record.account
+	// the class after / is dropped, QIF writes subcategories as Parent:Child
+	category, _, _ = strings.Cut(category, "/")
+	if category != "" {
+		t.Category = strings.Join(strings.Split(category, ":"), database.CategorySeparator)
+	}
+	return t, nil
+}
+
+// qifDateParts splits "01/31/2024", "1/31'24", "31.01.2024" or "2024-01-31" into its numbers
+func qifDateParts(s string) []int {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == '\'' || r == ' '
+	})
+	var parts []int
+	for _, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// detectDayFirst reports whether dates are written day first, a first number above 12
+// decides it. Quicken writes month first, so that is the default.
+func detectDayFirst(dates []string) bool {
+	for _, date := range dates {
+		parts := qifDateParts(date)
+		if len(parts) != 3 || parts[0] > 31 {
+			continue
+		}
+		if parts[0] > 12 {
+			return true
+		}
+		if parts[1] > 12 {
+			return false
+		}
+	}
+	return false
+}
+
+func parseQIFDate(s string, dayFirst bool) (time.Time, error) {
+	parts := qifDateParts(s)
+	if len(parts) != 3 {
+		return time.Time{}, errors.New("invalid date")
+	}
+	year, month, day := parts[2], parts[0], parts[1]
+	switch {
+	case parts[0] > 31:
+		year, month, day = parts[0], parts[1], parts[2]
+	case dayFirst:
+		month, day = parts[1], parts[0]
+	}
+	if year < 100 {
+		year += 1900
+		if year < 1970 {
+			year += 100
+		}
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, errors.New("invalid date")
+	}
+	return date, nil
+}
@@ -0,0 +1,130 @@
+package importer_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/importer"
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestReadQIF(t *testing.T) {
+	input := "!Type:Bank\r\n" +
+		"D31/01'24\r\nT-1,234.50\r\nPLandlord\r\nMJanuary\r\nLHousing:Rent\r\n^\r\n" +
+		"D1/2'24\r\nT100.00\r\nPTransfer\r\nL[Savings]\r\n^\r\n" +
+		"!Type:Cat\r\nNHousing\r\n^\r\n"
+	transactions, err := importer.ReadQIF(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 {
+		t.Fatal("expected 2 transactions, got", len(transactions))
+	}
+	rent, transfer := transactions[0], transactions[1]
+	if rent.Type != "expense" || rent.Amount != 123450 || rent.Description != "Landlord" || rent.Note != "January" {
+		t.Error("unexpected transaction", rent)
+	}
+	if rent.Category != "Housing > Rent" {
+		t.Error("expected Housing > Rent, got", rent.Category)
+	}
+	// 31/01 makes the whole file day first
+	if rent.OccurredAt != "2024-01-31 00:00:00" || transfer.OccurredAt != "2024-02-01 00:00:00" {
+		t.Error("unexpected dates", rent.OccurredAt, transfer.OccurredAt)
+	}
+	// money comes in from Savings
+	if transfer.Type != "transfer" || transfer.Account != "Savings" || transfer.ToAccount != "" {
+		t.Error("unexpected transfer", transfer)
+	}
+}
+
+func TestReadQIFDecimalComma(t *testing.T) {
+	input := "!Type:Bank\n" +
+		"D31.01.2024\nT-1.234,50\nPVermieter\n^\n" +
+		"D01.02.2024\nT3,5\nPErstattung\n^\n"
+	if _, err := importer.ReadQIF(strings.NewReader(input), false); err == nil {
+		t.Error("expected decimal commas to be rejected without --decimal-comma")
+	}
+	transactions, err := importer.ReadQIF(strings.NewReader(input), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 || transactions[0].Amount != 123450 || transactions[1].Amount != 350 {
+		t.Error("expected 1234.50 and 3.50, got", transactions)
+	}
+}
+
+func TestReadQIFTransferLegs(t *testing.T) {
+	// a Quicken export of two accounts writes both legs of every transfer
+	input := "!Account\nNChecking\nTBank\n^\n!Type:Bank\n" +
+		"D01/05/2024\nT-100.00\nPSavings\nL[Savings]\n^\n" +
+		"D01/05/2024\nT-100.00\nPSavings again\nL[Savings]\n^\n" +
+		"D01/09/2024\nT-20.00\nPCard\nL[Visa]\n^\n" +
+		"!Account\nNSavings\nTBank\n^\n!Type:Bank\n" +
+		"D01/05/2024\nT100.00\nPSavings\nL[Checking]\n^\n" +
+		"D01/05/2024\nT100.00\nPSavings again\nL[Checking]\n^\n" +
+		"D01/07/2024\nT50.00\nPFrom checking\nL[Checking]\n^\n"
+	transactions, err := importer.ReadQIF(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the 50.00 transfer only has its inflow leg in the file, the Visa account is not exported
+	if len(transactions) != 4 {
+		t.Fatal("expected 4 transfers, got", transactions)
+	}
+	for _, transfer := range transactions {
+		if transfer.Type != "transfer" || transfer.Account != "Checking" && transfer.ToAccount != "Checking" {
+			t.Error("unexpected transfer", transfer)
+		}
+	}
+	if last := transactions[3]; last.Account != "Checking" || last.ToAccount != "Savings" || last.Amount != 5000 {
+		t.Error("expected 50.00 from Checking to Savings, got", last)
+	}
+}
+
+func TestReadQIFErrors(t *testing.T) {
+	tests := []string{
+		"!Type:Bank\nD01/31/2024\nTabc\n^\n",
+		"!Type:Bank\nD02/30/2024\nT1.00\n^\n",
+		"!Type:Bank\nT1.00\n^\n",
+	}
+	for _, input := range tests {
+		if _, err := importer.ReadQIF(strings.NewReader(input), false); err == nil {
+			t.Error("expected error for", input)
+		}
+	}
+}
+
+func TestQIFRoundTrip(t *testing.T) {
+	want := []database.Transaction{
+		{Type: "expense", Amount: 1250, Description: "Groceries", Note: "weekly shop\nwith receipt", Category: "Food > Groceries", Account: "checking", OccurredAt: "2024-01-05T00:00:00Z"},
+		{Type: "income", Amount: 250000, Description: "Salary", Account: "checking", OccurredAt: "2024-01-31T00:00:00Z"},
+		{Type: "transfer", Amount: 10000, Description: "Savings", Account: "checking", ToAccount: "savings", OccurredAt: "2024-02-01T00:00:00Z"},
+		{Type: "expense", Amount: 300, Description: "Coffee", Account: "cash", OccurredAt: "2024-02-13T00:00:00Z"},
+	}
+	var b bytes.Buffer
+	if err := list.WriteQIF(&b, want); err != nil {
+		t.Fatal(err)
+	}
+	if legs := strings.Count(b.String(), "L["); legs != 2 {
+		t.Error("expected both legs of the transfer to be written, got", legs)
+	}
+	got, err := importer.ReadQIF(&b, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatal("expected", len(want), "transactions, got", len(got))
+	}
+	if got[0].Note != "weekly shop with receipt" {
+		t.Errorf("expected the note to survive the round trip, got %q", got[0].Note)
+	}
+	for i := range want {
+		w, g := want[i], got[i]
+		if g.Type != w.Type || g.Amount != w.Amount || g.Description != w.Description || g.Category != w.Category ||
+			g.Account != w.Account || g.ToAccount != w.ToAccount || g.OccurredAt != strings.Replace(w.OccurredAt[:19], "T", " ", 1) {
+			t.Errorf("transaction %d: expected %+v, got %+v", i, w, g)
+		}
+	}
+}
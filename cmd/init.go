@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/elliot40404/acc/pkg/database"
 	"github.com/elliot40404/acc/pkg/utils"
@@ -22,15 +23,15 @@ func init() {
 
 func InitApp(cmd *cobra.Command, args []string) {
 	// check if the application has already been initialized
-	if utils.IsInitialized() {
+	if utils.IsInitialized(database.DBPATH) {
 		utils.PrintError(nil, "Application has already been initialized", false)
 		os.Exit(0)
 	}
 
-	dbPath := utils.DBPATH()
-	appDir := utils.APPDIR()
+	dbPath := database.DBPATH
+	appDir := filepath.Dir(dbPath)
 	debugMode, _ := cmd.Flags().GetBool("verbose")
-	// make .acc directory if it doesn't exist
+	// make the directory of the database if it doesn't exist
 	if _, err := os.Stat(appDir); os.IsNotExist(err) {
 		err = os.MkdirAll(appDir, 0755)
 		if err != nil {
@@ -49,9 +50,4 @@ func InitApp(cmd *cobra.Command, args []string) {
 	if err != nil {
 		utils.PrintError(err, "Failed to initialize database", debugMode)
 	}
-	// create marker to indicate that the database has been initialized
-	_, err = os.Create(appDir + "/.initialized")
-	if err != nil {
-		utils.PrintError(err, "Failed to create init file", debugMode)
-	}
 }
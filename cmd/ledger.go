@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/backup"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var ledgerCmd = &cobra.Command{
+	Use:   "ledger",
+	Short: "Keep separate books in named ledgers",
+	Long: `Keep separate books, e.g. for personal and business finances, in named ledgers. Every
+ledger has its own database and backups. Commands use the ledger picked with acc ledger use,
+--ledger picks another one for a single command.`,
+	Example: `acc ledger create business
+acc --ledger business add -t expense -d "Printer" -a 120
+acc ledger use business`,
+}
+
+var ledgerCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a ledger",
+	Args:  cobra.ExactArgs(1),
+	Run:   LedgerCreate,
+}
+
+var ledgerLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List ledgers",
+	Args:  cobra.NoArgs,
+	Run:   LedgerLs,
+}
+
+var ledgerUseCmd = &cobra.Command{
+	Use:     "use <name>",
+	Short:   "Use a ledger from now on",
+	Example: `acc ledger use default`,
+	Args:    cobra.ExactArgs(1),
+	Run:     LedgerUse,
+}
+
+var ledgerRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a ledger and its backups",
+	Long: `Remove a ledger and its backups. A last backup of the ledger is saved to the backups
+directory of the default ledger.`,
+	Args: cobra.ExactArgs(1),
+	Run:  LedgerRm,
+}
+
+func init() {
+	RootCmd.AddCommand(ledgerCmd)
+	ledgerCmd.AddCommand(ledgerCreateCmd, ledgerLsCmd, ledgerUseCmd, ledgerRmCmd)
+}
+
+func LedgerCreate(cmd *cobra.Command, args []string) {
+	name := args[0]
+	if err := utils.ValidateLedgerName(name); err != nil {
+		fmt.Println(err)
+		return
+	}
+	path := utils.LedgerPath(name)
+	if utils.IsInitialized(path) {
+		fmt.Printf("ledger %s already exists\n", name)
+		return
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Printf("Would create ledger %s at %s\n", name, path)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		fmt.Println(err)
+		return
+	}
+	previous := database.DBPATH
+	database.DBPATH = path
+	err := database.InitApplication()
+	database.DBPATH = previous
+	if err != nil {
+		fmt.Println(err)
+		os.Remove(path)
+		return
+	}
+	fmt.Printf("Created ledger %s, switch to it with: acc ledger use %s\n", name, name)
+}
+
+func LedgerLs(cmd *cobra.Command, args []string) {
+	names, err := utils.Ledgers()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	current := utils.CurrentLedger()
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Ledger", "In use", "Path"})
+	for _, name := range names {
+		inUse := ""
+		if name == current {
+			inUse = "*"
+		}
+		t.AppendRow(table.Row{name, inUse, utils.LedgerPath(name)})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
+
+func LedgerUse(cmd *cobra.Command, args []string) {
+	name := args[0]
+	if err := ledgerExists(name); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Println("Would use ledger", name)
+		return
+	}
+	if err := utils.SetCurrentLedger(name); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Using ledger", name)
+	if os.Getenv("ACC_DB") != "" {
+		fmt.Fprintln(os.Stderr, "ACC_DB is set and takes precedence over the ledger in use")
+	}
+}
+
+func LedgerRm(cmd *cobra.Command, args []string) {
+	name := args[0]
+	if err := ledgerExists(name); err != nil {
+		fmt.Println(err)
+		return
+	}
+	switch {
+	case name == utils.DefaultLedger:
+		fmt.Println("the default ledger cannot be removed")
+		return
+	case name == utils.CurrentLedger():
+		fmt.Printf("ledger %s is in use, switch to another ledger first with: acc ledger use default\n", name)
+		return
+	}
+	path := utils.LedgerPath(name)
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Println("Would remove ledger", name)
+		return
+	}
+	fmt.Printf("Removing ledger %s. ", name)
+	if !utils.PromptConfirmation() {
+		fmt.Println("Aborted")
+		return
+	}
+	dir := filepath.Join(utils.LedgerDir(utils.DefaultLedger), "backups")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Println(err)
+		return
+	}
+	saved := backup.NewPath(dir, "rm-ledger-"+name, time.Now())
+	if err := database.BackupFile(path, saved); err != nil {
+		fmt.Println("failed to back up the ledger, nothing was removed:", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Backup saved to", saved)
+	if err := os.RemoveAll(utils.LedgerDir(name)); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("Removed ledger", name)
+}
+
+func ledgerExists(name string) error {
+	if err := utils.ValidateLedgerName(name); err != nil {
+		return err
+	}
+	if !utils.IsInitialized(utils.LedgerPath(name)) {
+		return fmt.Errorf("ledger %s does not exist, see acc ledger ls", name)
+	}
+	return nil
+}
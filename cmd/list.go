@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/elliot40404/acc/cmd/list"
 	"github.com/elliot40404/acc/pkg/database"
@@ -27,12 +29,20 @@ func init() {
 	listCmd.Flags().IntP("page", "p", 1, "page number")
 	listCmd.Flags().IntP("limit", "l", 10, "limit per page")
 	listCmd.Flags().StringP("date", "d", "", "filter by date or date-ranges")
-	listCmd.Flags().StringP("type", "t", "", "filter by type (income, expense)")
+	listCmd.Flags().StringP("type", "t", "", "filter by type (income, expense, transfer)")
 	listCmd.Flags().StringP("amount", "a", "", "filter by amount")
-	listCmd.Flags().StringP("sort", "s", "", "sort by date, amount")
+	listCmd.Flags().StringP("sort", "s", "", "sort by date, amt or relevance (with --search)")
 	listCmd.Flags().StringP("desc", "D", "", "filter by description")
-	listCmd.Flags().StringP("format", "f", "table", "print in table/json/csv format")
-	listCmd.Flags().StringSliceVarP(&columns, "columns", "c", []string{}, "columns to print (id, type, amt, desc, date) (default: all) (only works with table format) (example: -c 'id,type' or -c id -c type)")
+	listCmd.Flags().StringP("search", "S", "", "full-text search in descriptions and notes, words match as prefixes (e.g. 'coffee OR tea -starbucks', '\"exact phrase\"')")
+	listCmd.Flags().StringSlice("tag", []string{}, "filter by tag, can be repeated. prefix with ! to exclude (e.g. --tag trip --tag '!work')")
+	listCmd.Flags().String("tag-mode", "any", "match any or all of the given tags")
+	listCmd.Flags().String("currency", "", "filter by currency (e.g. EUR)")
+	listCmd.Flags().String("account", "", "filter by account, transfers into the account are included")
+	listCmd.Flags().String("category", "", "filter by category, including its subcategories (e.g. \"Food > Groceries\")")
+	listCmd.Flags().Bool("include-deleted", false, "include transactions in the trash")
+	listCmd.Flags().StringP("format", "f", "table", "print in table/json/csv/qif/ledger/hledger/beancount format")
+	addJournalFlags(listCmd)
+	listCmd.Flags().StringSliceVarP(&columns, "columns", "c", []string{}, "columns to print (id, type, amt, cur, base, desc, note, cat, tags, acct, date, deleted) (default: all) (only works with table format) (example: -c 'id,type' or -c id -c type)")
 }
 
 func List(cmd *cobra.Command, args []string) {
@@ -48,11 +58,24 @@ func List(cmd *cobra.Command, args []string) {
 		Sort:     cmd.Flag("sort").Value.String(),
 		SortAsc:  cmd.Flag("asc").Value.String() == "true",
 		Desc:     cmd.Flag("desc").Value.String(),
+		Search:   cmd.Flag("search").Value.String(),
+		Category: cmd.Flag("category").Value.String(),
+		TagMode:  cmd.Flag("tag-mode").Value.String(),
+		Account:  cmd.Flag("account").Value.String(),
+		Currency: strings.ToUpper(cmd.Flag("currency").Value.String()),
 		Columns:  columns,
 		IsHRTime: cmd.Flag("htime").Value.String() == "true",
 		Format:   cmd.Flag("format").Value.String(),
 		IsPretty: cmd.Flag("pretty").Value.String() == "true",
 	}
+	queryConfig.IncludeDeleted, _ = cmd.Flags().GetBool("include-deleted")
+	queryConfig.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	journal, err := journalConfig(cmd)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	queryConfig.Journal = journal
 	if cmd.Flag("date-help").Value.String() == "true" {
 		printDateHelp()
 		return
@@ -60,11 +83,25 @@ func List(cmd *cobra.Command, args []string) {
 	if queryConfig.Verbose {
 		fmt.Printf("%#v\n", queryConfig)
 	}
-	err := list.ValidateConfig(&queryConfig)
+	err = list.ValidateConfig(&queryConfig)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
+	if queryConfig.Category != "" {
+		queryConfig.CategoryID, err = database.NewCategoryRepository().FindCategory(queryConfig.Category)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if queryConfig.Account != "" {
+		queryConfig.AccountID, err = database.NewAccountRepository().FindAccount(queryConfig.Account)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
 	isInteractive := cmd.Flag("interactive").Value.String() == "true"
 	if isInteractive {
 		list.InteractiveListRenderer(queryConfig)
@@ -73,6 +110,37 @@ func List(cmd *cobra.Command, args []string) {
 	list.NonInteractiveListRenderer(queryConfig)
 }
 
+// addJournalFlags registers the account names used by the ledger, hledger and beancount
+// formats
+func addJournalFlags(c *cobra.Command) {
+	c.Flags().String("assets-account", list.DefaultJournalConfig.Assets, "journal account acc accounts are booked under (ledger/hledger/beancount)")
+	c.Flags().String("income-account", list.DefaultJournalConfig.Income, "journal account income categories are booked under (ledger/hledger/beancount)")
+	c.Flags().String("expenses-account", list.DefaultJournalConfig.Expenses, "journal account expense categories are booked under (ledger/hledger/beancount)")
+	c.Flags().StringSlice("journal-account", []string{}, "map an account or category to a journal account, can be repeated (e.g. --journal-account checking=Assets:Bank:Checking --journal-account Food=Expenses:Dining)")
+}
+
+func journalConfig(c *cobra.Command) (database.JournalConfig, error) {
+	config := database.JournalConfig{
+		Assets:      c.Flag("assets-account").Value.String(),
+		Liabilities: list.DefaultJournalConfig.Liabilities,
+		Income:      c.Flag("income-account").Value.String(),
+		Expenses:    c.Flag("expenses-account").Value.String(),
+		Accounts:    map[string]string{},
+	}
+	if f := c.Flags().Lookup("liabilities-account"); f != nil {
+		config.Liabilities = f.Value.String()
+	}
+	mappings, _ := c.Flags().GetStringSlice("journal-account")
+	for _, mapping := range mappings {
+		name, account, ok := strings.Cut(mapping, "=")
+		if !ok || strings.TrimSpace(account) == "" {
+			return config, errors.New("invalid journal account '" + mapping + "'. expected name=Journal:Account")
+		}
+		config.Accounts[strings.TrimSpace(name)] = strings.TrimSpace(account)
+	}
+	return config, nil
+}
+
 func printDateHelp() {
 	fmt.Println("Supported date formats:")
 	fmt.Println("Builtins: today, yesterday, thisweek, lastweek, thismonth, lastmonth, thisyear, lastyear")
@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/elliot40404/acc/pkg/database"
 	"github.com/elliot40404/acc/pkg/utils"
@@ -35,7 +36,15 @@ func csvWriter(transactions []database.Transaction) {
 		"ID",
 		"Type",
 		"Amt",
+		"Currency",
+		"BaseAmt",
 		"Desc",
+		"Note",
+		"Category",
+		"Tags",
+		"Account",
+		"ToAccount",
+		"Date",
 		"CreatedAt",
 		"UpdatedAt",
 	})
@@ -43,8 +52,16 @@ func csvWriter(transactions []database.Transaction) {
 		rows = append(rows, []string{
 			strconv.Itoa(transaction.ID),
 			transaction.Type,
-			strconv.FormatFloat(transaction.Amount, 'f', 2, 64),
+			transaction.Amount.String(),
+			transaction.Currency,
+			baseAmount(transaction, ""),
 			transaction.Description,
+			transaction.Note,
+			transaction.Category,
+			strings.Join(transaction.Tags, ","),
+			transaction.Account,
+			transaction.ToAccount,
+			transaction.OccurredAt,
 			transaction.CreatedAt,
 			transaction.UpdatedAt,
 		})
@@ -93,24 +110,31 @@ func getTableHeader(queryConfig database.TransactionConfig) table.Row {
 
 func getTableRows(transactions []database.Transaction, queryConfig database.TransactionConfig) []table.Row {
 	rows := []table.Row{}
+	base := ""
+	if utils.CurrencySymbol {
+		base, _ = database.NewFxRepository().GetBaseCurrency()
+	}
 	for _, transaction := range transactions {
 		if queryConfig.IsHRTime {
-			transaction.CreatedAt = utils.HRTime(transaction.CreatedAt)
+			transaction.OccurredAt = utils.HRTime(transaction.OccurredAt)
+		} else {
+			transaction.OccurredAt = utils.FormatDate(transaction.OccurredAt)
 		}
-		row := getTableRow(transaction, queryConfig)
+		row := getTableRow(transaction, queryConfig, base)
 		rows = append(rows, row)
 	}
 	return rows
 }
 
-func getTableRow(transaction database.Transaction, queryConfig database.TransactionConfig) table.Row {
+// getTableRow renders the columns of a transaction, base is the base currency
+func getTableRow(transaction database.Transaction, queryConfig database.TransactionConfig, base string) table.Row {
 	if len(queryConfig.Columns) == 0 {
 		return table.Row{
 			strconv.Itoa(transaction.ID),
 			transaction.Type,
-			transaction.Amount,
+			utils.FormatAmount(transaction.Amount, transaction.Currency),
 			transaction.Description,
-			transaction.CreatedAt,
+			transaction.OccurredAt,
 		}
 	}
 	row := table.Row{}
@@ -121,16 +145,54 @@ func getTableRow(transaction database.Transaction, queryConfig database.Transact
 		case "type":
 			row = append(row, transaction.Type)
 		case "amt":
-			row = append(row, transaction.Amount)
+			row = append(row, utils.FormatAmount(transaction.Amount, transaction.Currency))
 		case "desc":
 			row = append(row, transaction.Description)
+		case "note":
+			row = append(row, transaction.Note)
+		case "cat":
+			row = append(row, transaction.Category)
+		case "tags":
+			row = append(row, strings.Join(transaction.Tags, ", "))
+		case "acct":
+			row = append(row, accountLabel(transaction))
+		case "cur":
+			row = append(row, transaction.Currency)
+		case "base":
+			row = append(row, baseAmount(transaction, base))
 		case "date":
-			row = append(row, transaction.CreatedAt)
+			row = append(row, transaction.OccurredAt)
+		case "deleted":
+			row = append(row, deletedAt(transaction))
 		}
 	}
 	return row
 }
 
+// deletedAt is empty unless the transaction is in the trash
+func deletedAt(transaction database.Transaction) string {
+	if transaction.DeletedAt == nil {
+		return ""
+	}
+	return *transaction.DeletedAt
+}
+
+// baseAmount is empty when no exchange rate to the base currency is known, base is only
+// needed to show its symbol
+func baseAmount(transaction database.Transaction, base string) string {
+	if transaction.BaseAmount == nil {
+		return ""
+	}
+	return utils.FormatAmount(*transaction.BaseAmount, base)
+}
+
+func accountLabel(transaction database.Transaction) string {
+	if transaction.ToAccount != "" {
+		return transaction.Account + " -> " + transaction.ToAccount
+	}
+	return transaction.Account
+}
+
 func printTableSummary(queryConfig database.TransactionConfig, totalTx int, transactions int, interactive bool) string {
 	totalPages := int(math.Ceil(float64(totalTx) / float64(queryConfig.Limit)))
 	if queryConfig.All {
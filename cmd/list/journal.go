@@ -0,0 +1,199 @@
+package list
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/itlightning/dateparse"
+)
+
+// plain text accounting formats written by WriteJournal
+var journalFormats = []string{"ledger", "hledger", "beancount"}
+
+// DefaultJournalConfig uses the root account names all three tools expect
+var DefaultJournalConfig = database.JournalConfig{
+	Assets:      "Assets",
+	Liabilities: "Liabilities",
+	Income:      "Income",
+	Expenses:    "Expenses",
+}
+
+type journalPosting struct {
+	account string
+	amount  money.Money
+}
+
+type journalEntry struct {
+	date        string
+	description string
+	currency    string
+	tags        []string
+	postings    []journalPosting
+}
+
+// WriteJournal writes every transaction as a balanced entry with two postings: income and
+// expenses against the asset account they are booked on, transfers between two asset accounts
+func WriteJournal(w io.Writer, transactions []database.Transaction, format string, config database.JournalConfig) error {
+	var entries []journalEntry
+	for _, transaction := range transactions {
+		date, err := dateparse.ParseAny(transaction.OccurredAt)
+		if err != nil {
+			return fmt.Errorf("transaction %d: invalid date %q", transaction.ID, transaction.OccurredAt)
+		}
+		asset := journalAccount(config.Assets, transaction.Account, config, format)
+		var other string
+		amount := transaction.Amount
+		switch transaction.Type {
+		case "income":
+			other = journalAccount(config.Income, transaction.Category, config, format)
+			amount = -amount
+		case "expense":
+			other = journalAccount(config.Expenses, transaction.Category, config, format)
+		case "transfer":
+			other = journalAccount(config.Assets, transaction.ToAccount, config, format)
+		}
+		entries = append(entries, journalEntry{
+			date:        date.Format("2006-01-02"),
+			description: transaction.Description,
+			currency:    transaction.Currency,
+			tags:        transaction.Tags,
+			postings: []journalPosting{
+				{account: other, amount: amount},
+				{account: asset, amount: -amount},
+			},
+		})
+	}
+	b := bufio.NewWriter(w)
+	if format == "beancount" {
+		writeBeancountOpens(b, entries)
+	}
+	for _, entry := range entries {
+		switch format {
+		case "beancount":
+			tags := ""
+			for _, tag := range entry.tags {
+				tags += " #" + sanitizeJournalName(tag, "beancount")
+			}
+			fmt.Fprintf(b, "%s * %q%s\n", entry.date, entry.description, tags)
+		case "hledger":
+			fmt.Fprintf(b, "%s %s", entry.date, journalDescription(entry.description))
+			if len(entry.tags) > 0 {
+				var tags []string
+				for _, tag := range entry.tags {
+					tags = append(tags, sanitizeJournalName(tag, format)+":")
+				}
+				fmt.Fprintf(b, "  ; %s", strings.Join(tags, ", "))
+			}
+			fmt.Fprintln(b)
+		default:
+			fmt.Fprintf(b, "%s %s\n", entry.date, journalDescription(entry.description))
+			if len(entry.tags) > 0 {
+				var tags []string
+				for _, tag := range entry.tags {
+					tags = append(tags, sanitizeJournalName(tag, format))
+				}
+				fmt.Fprintf(b, "    ; :%s:\n", strings.Join(tags, ":"))
+			}
+		}
+		for _, posting := range entry.postings {
+			fmt.Fprintf(b, "    %s  %s %s\n", posting.account, posting.amount, entry.currency)
+		}
+		fmt.Fprintln(b)
+	}
+	return b.Flush()
+}
+
+func journalWriter(transactions []database.Transaction, queryConfig database.TransactionConfig) {
+	if err := WriteJournal(os.Stdout, transactions, queryConfig.Format, queryConfig.Journal); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// beancount refuses postings to accounts that were not opened before
+func writeBeancountOpens(b *bufio.Writer, entries []journalEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	first := entries[0].date
+	seen := map[string]bool{}
+	var accounts []string
+	for _, entry := range entries {
+		if entry.date < first {
+			first = entry.date
+		}
+		for _, posting := range entry.postings {
+			if !seen[posting.account] {
+				seen[posting.account] = true
+				accounts = append(accounts, posting.account)
+			}
+		}
+	}
+	sort.Strings(accounts)
+	for _, account := range accounts {
+		fmt.Fprintf(b, "%s open %s\n", first, account)
+	}
+	fmt.Fprintln(b)
+}
+
+// journalAccount places an acc account or category path below root, unless it (or one of
+// its parents) is mapped to another journal account
+func journalAccount(root string, path string, config database.JournalConfig, format string) string {
+	if path == "" {
+		if mapped, ok := config.Accounts[""]; ok {
+			return mapped
+		}
+		return root + ":Uncategorized"
+	}
+	segments := strings.Split(path, database.CategorySeparator)
+	for i := len(segments); i > 0; i-- {
+		if mapped, ok := config.Accounts[strings.Join(segments[:i], database.CategorySeparator)]; ok {
+			root, segments = mapped, segments[i:]
+			break
+		}
+	}
+	account := root
+	for _, segment := range segments {
+		name := sanitizeJournalName(segment, format)
+		// beancount account names start with a capital letter or digit
+		r, size := utf8.DecodeRuneInString(name)
+		account += ":" + string(unicode.ToUpper(r)) + name[size:]
+	}
+	return account
+}
+
+// sanitizeJournalName makes an account or tag name safe to write, beancount only allows
+// letters, digits and dashes while ledger and hledger read two spaces as the end of a name
+// and use : to separate levels and tags
+func sanitizeJournalName(name string, format string) string {
+	if format == "beancount" {
+		name = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+				return r
+			}
+			return '-'
+		}, name)
+		for strings.Contains(name, "--") {
+			name = strings.ReplaceAll(name, "--", "-")
+		}
+		name = strings.Trim(name, "-")
+	} else {
+		name = strings.Join(strings.Fields(strings.ReplaceAll(name, ":", "-")), " ")
+	}
+	if name == "" {
+		return "Unnamed"
+	}
+	return name
+}
+
+// journalDescription drops ; which starts a comment in ledger and hledger
+func journalDescription(description string) string {
+	return strings.ReplaceAll(description, ";", ",")
+}
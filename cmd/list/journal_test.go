@@ -0,0 +1,98 @@
+package list_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+var journalTransactions = []database.Transaction{
+	{Type: "expense", Amount: 1250, Currency: "USD", Description: "Groceries", Category: "Food > Groceries", Account: "checking", Tags: []string{"weekly"}, OccurredAt: "2024-01-05T00:00:00Z"},
+	{Type: "income", Amount: 250000, Currency: "USD", Description: "Salary", Account: "checking", OccurredAt: "2024-01-31T00:00:00Z"},
+	{Type: "transfer", Amount: 10000, Currency: "USD", Description: "Savings", Account: "checking", ToAccount: "rainy day", OccurredAt: "2024-02-01T00:00:00Z"},
+}
+
+func TestWriteJournal(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"ledger", `2024-01-05 Groceries
+    ; :weekly:
+    Expenses:Food:Groceries  12.50 USD
+    Assets:Checking  -12.50 USD
+
+2024-01-31 Salary
+    Income:Uncategorized  -2500.00 USD
+    Assets:Checking  2500.00 USD
+
+2024-02-01 Savings
+    Assets:Rainy day  100.00 USD
+    Assets:Checking  -100.00 USD
+
+`},
+		{"hledger", `2024-01-05 Groceries  ; weekly:
+    Expenses:Food:Groceries  12.50 USD
+    Assets:Checking  -12.50 USD
+
+2024-01-31 Salary
+    Income:Uncategorized  -2500.00 USD
+    Assets:Checking  2500.00 USD
+
+2024-02-01 Savings
+    Assets:Rainy day  100.00 USD
+    Assets:Checking  -100.00 USD
+
+`},
+		{"beancount", `2024-01-05 open Assets:Checking
+2024-01-05 open Assets:Rainy-day
+2024-01-05 open Expenses:Food:Groceries
+2024-01-05 open Income:Uncategorized
+
+2024-01-05 * "Groceries" #weekly
+    Expenses:Food:Groceries  12.50 USD
+    Assets:Checking  -12.50 USD
+
+2024-01-31 * "Salary"
+    Income:Uncategorized  -2500.00 USD
+    Assets:Checking  2500.00 USD
+
+2024-02-01 * "Savings"
+    Assets:Rainy-day  100.00 USD
+    Assets:Checking  -100.00 USD
+
+`},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := list.WriteJournal(&b, journalTransactions, tt.format, list.DefaultJournalConfig); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tt.format, tt.want, b.String())
+		}
+	}
+}
+
+func TestWriteJournalMappedAccounts(t *testing.T) {
+	config := list.DefaultJournalConfig
+	config.Accounts = map[string]string{
+		"checking": "Assets:Bank:Checking",
+		"Food":     "Expenses:Dining",
+	}
+	var b bytes.Buffer
+	if err := list.WriteJournal(&b, journalTransactions[:1], "ledger", config); err != nil {
+		t.Fatal(err)
+	}
+	want := `2024-01-05 Groceries
+    ; :weekly:
+    Expenses:Dining:Groceries  12.50 USD
+    Assets:Bank:Checking  -12.50 USD
+
+`
+	if b.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,75 @@
+package list
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/itlightning/dateparse"
+)
+
+// qifEntry is a transaction in the section of an account, inflow is the leg of a transfer
+// written to the destination account
+type qifEntry struct {
+	transaction database.Transaction
+	inflow      bool
+}
+
+// WriteQIF writes one !Type:Bank section per account. Transfers are written twice like
+// Quicken and GnuCash do: on the account the money leaves with the destination as [Account]
+// category, and on the destination with the source as [Account] category.
+func WriteQIF(w io.Writer, transactions []database.Transaction) error {
+	var accounts []string
+	byAccount := map[string][]qifEntry{}
+	add := func(account string, entry qifEntry) {
+		if _, ok := byAccount[account]; !ok {
+			accounts = append(accounts, account)
+		}
+		byAccount[account] = append(byAccount[account], entry)
+	}
+	for _, transaction := range transactions {
+		add(transaction.Account, qifEntry{transaction: transaction})
+		if transaction.Type == "transfer" && transaction.ToAccount != "" {
+			add(transaction.ToAccount, qifEntry{transaction: transaction, inflow: true})
+		}
+	}
+	b := bufio.NewWriter(w)
+	for _, account := range accounts {
+		fmt.Fprintf(b, "!Account\nN%s\nTBank\n^\n!Type:Bank\n", account)
+		for _, entry := range byAccount[account] {
+			transaction := entry.transaction
+			date, err := dateparse.ParseAny(transaction.OccurredAt)
+			if err != nil {
+				return fmt.Errorf("transaction %d: invalid date %q", transaction.ID, transaction.OccurredAt)
+			}
+			amount := transaction.Amount
+			if transaction.Type != "income" && !entry.inflow {
+				amount = -amount
+			}
+			fmt.Fprintf(b, "D%s\nT%s\nP%s\n", date.Format("01/02/2006"), amount, transaction.Description)
+			if transaction.Note != "" {
+				// fields are single lines
+				fmt.Fprintf(b, "M%s\n", strings.Join(strings.Fields(transaction.Note), " "))
+			}
+			switch {
+			case entry.inflow:
+				fmt.Fprintf(b, "L[%s]\n", transaction.Account)
+			case transaction.Type == "transfer":
+				fmt.Fprintf(b, "L[%s]\n", transaction.ToAccount)
+			case transaction.Category != "":
+				fmt.Fprintf(b, "L%s\n", strings.ReplaceAll(transaction.Category, database.CategorySeparator, ":"))
+			}
+			fmt.Fprintln(b, "^")
+		}
+	}
+	return b.Flush()
+}
+
+func qifWriter(transactions []database.Transaction) {
+	if err := WriteQIF(os.Stdout, transactions); err != nil {
+		fmt.Println(err)
+	}
+}
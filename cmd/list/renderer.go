@@ -14,7 +14,6 @@ import (
 
 var page int = 1
 var QC *database.TransactionConfig
-var DB database.TransactionRepository = database.NewTransactionRepository()
 var TotalTx int
 
 func newModel(qc database.TransactionConfig, totalPages int) model {
@@ -84,7 +83,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m model) View() string {
 	var b strings.Builder
-	transactions, err := DB.GetTransactionsWithConfig(*QC)
+	transactions, err := database.NewTransactionRepository().GetTransactionsWithConfig(*QC)
 	if err != nil {
 		fmt.Println(err)
 		return ""
@@ -99,7 +98,7 @@ func (m model) View() string {
 }
 
 func InteractiveListRenderer(queryConfig database.TransactionConfig) {
-	totalTx, err := DB.GetTransactionCountWithConfig(queryConfig)
+	totalTx, err := database.NewTransactionRepository().GetTransactionCountWithConfig(queryConfig)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -118,8 +117,9 @@ func InteractiveListRenderer(queryConfig database.TransactionConfig) {
 }
 
 func NonInteractiveListRenderer(queryConfig database.TransactionConfig) {
-	transactions, err := DB.GetTransactionsWithConfig(queryConfig)
-	totalTx, _ := DB.GetTransactionCountWithConfig(queryConfig)
+	db := database.NewTransactionRepository()
+	transactions, err := db.GetTransactionsWithConfig(queryConfig)
+	totalTx, _ := db.GetTransactionCountWithConfig(queryConfig)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -132,7 +132,21 @@ func NonInteractiveListRenderer(queryConfig database.TransactionConfig) {
 		jsonWriter(transactions, queryConfig.IsPretty)
 	case "csv":
 		csvWriter(transactions)
+	case "qif":
+		qifWriter(transactions)
+	case "ledger", "hledger", "beancount":
+		journalWriter(transactions, queryConfig)
 	default:
 		tableWriter(transactions, totalTx, queryConfig, false)
 	}
 }
+
+// PreviewRenderer prints transactions that are not stored yet, e.g. during a dry run import
+func PreviewRenderer(transactions []database.Transaction) {
+	queryConfig := database.TransactionConfig{
+		Page:    1,
+		All:     true,
+		Columns: []string{"date", "type", "amt", "cur", "desc", "cat", "tags", "acct"},
+	}
+	tableWriter(transactions, len(transactions), queryConfig, false)
+}
@@ -2,6 +2,8 @@ package list
 
 import (
 	"errors"
+	"regexp"
+	"strings"
 
 	"github.com/elliot40404/acc/pkg/database"
 	"github.com/elliot40404/acc/pkg/utils"
@@ -10,12 +12,17 @@ import (
 var sortables = []string{
 	"date",
 	"amt",
+	"relevance",
 }
 
 var validFormats = []string{
 	"table",
 	"json",
 	"csv",
+	"qif",
+	"ledger",
+	"hledger",
+	"beancount",
 }
 
 var validColumns = []string{
@@ -23,9 +30,20 @@ var validColumns = []string{
 	"type",
 	"amt",
 	"desc",
+	"note",
+	"cat",
+	"tags",
+	"acct",
+	"cur",
+	"base",
 	"date",
+	"deleted",
 }
 
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var tagPattern = regexp.MustCompile(`^#?[\p{L}\p{N}_.:-]+$`)
+
 func ValidateConfig(config *database.TransactionConfig) error {
 	if err := validateTxType(config.TxType); err != nil {
 		return err
@@ -39,15 +57,64 @@ func ValidateConfig(config *database.TransactionConfig) error {
 	if err := validateAmount(config.Amount); err != nil {
 		return err
 	}
-	if err := validateSort(config.Sort); err != nil {
+	if err := ValidateSort(config.Sort); err != nil {
+		return err
+	}
+	if err := validateSearch(config.Search, config.Sort); err != nil {
+		return err
+	}
+	if err := ValidateFormat(config.Format); err != nil {
+		return err
+	}
+	if err := ValidateColumns(config.Columns); err != nil {
+		return err
+	}
+	if err := ValidateTags(config.Tags, true); err != nil {
+		return err
+	}
+	if err := validateTagMode(config.TagMode); err != nil {
 		return err
 	}
+	if config.Currency != "" {
+		if err := ValidateCurrency(config.Currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ValidateFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	for _, validFormat := range validFormats {
+		if format == validFormat {
+			return nil
+		}
+	}
+	return errors.New("invalid format. format must be one of 'table', 'json', 'csv', 'qif', 'ledger', 'hledger' or 'beancount'")
+}
+
+// ValidateColumns checks the columns of the table format given with --columns or
+// list.columns, the table would leave unknown ones out
+func ValidateColumns(columns []string) error {
+	for _, column := range columns {
+		valid := false
+		for _, validColumn := range validColumns {
+			if column == validColumn {
+				valid = true
+			}
+		}
+		if !valid {
+			return errors.New("invalid column '" + column + "'. columns must be one of " + strings.Join(validColumns, ", "))
+		}
+	}
 	return nil
 }
 
 func validateTxType(txType string) error {
-	if txType != "" && txType != "income" && txType != "expense" {
-		return errors.New("invalid type. type must be either income or expense")
+	if txType != "" && txType != "income" && txType != "expense" && txType != "transfer" {
+		return errors.New("invalid type. type must be one of income, expense or transfer")
 	}
 	return nil
 }
@@ -79,14 +146,56 @@ func validateAmount(amount string) error {
 	return nil
 }
 
-func validateSort(sort string) error {
+func ValidateSort(sort string) error {
 	if sort != "" {
 		for _, sortable := range sortables {
 			if sort == sortable {
 				return nil
 			}
 		}
-		return errors.New("invalid sort. sort must be either 'date' or 'amt'")
+		return errors.New("invalid sort. sort must be one of 'date', 'amt' or 'relevance'")
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+func validateSearch(search, sort string) error {
+	if search == "" {
+		if sort == "relevance" {
+			return errors.New("sorting by relevance needs a search, use --search")
+		}
+		return nil
+	}
+	_, err := database.FTSQuery(search)
+	return err
+}
+
+// ValidateTags checks tag names, negated tags ("!work") are only valid when allowNegation is set
+func ValidateTags(tags []string, allowNegation bool) error {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "!") {
+			if !allowNegation {
+				return errors.New("invalid tag. tags cannot start with '!'")
+			}
+			tag = tag[1:]
+		}
+		if !tagPattern.MatchString(tag) {
+			return errors.New("invalid tag '" + tag + "'. tags may only contain letters, digits, '_', '.', ':' and '-'")
+		}
+	}
+	return nil
+}
+
+func validateTagMode(mode string) error {
+	if mode != "" && mode != "any" && mode != "all" {
+		return errors.New("invalid tag mode. tag mode must be either 'any' or 'all'")
+	}
+	return nil
+}
+
+// ValidateCurrency expects an upper case ISO 4217 code like EUR
+func ValidateCurrency(currency string) error {
+	if !currencyPattern.MatchString(currency) {
+		return errors.New("invalid currency '" + currency + "'. currency must be a 3 letter code like EUR or USD")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package list_test
+
+import (
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestValidateConfigColumns(t *testing.T) {
+	config := database.TransactionConfig{Page: 1, Limit: 10, Columns: []string{"id", "desc", "tags", "acct"}}
+	if err := list.ValidateConfig(&config); err != nil {
+		t.Error("expected valid columns, got", err)
+	}
+	// acct is the column, account is only the flag
+	config.Columns = []string{"id", "desc", "tags", "account"}
+	if err := list.ValidateConfig(&config); err == nil {
+		t.Error("expected the unknown column account to be rejected")
+	}
+}
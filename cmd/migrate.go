@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Show and apply database schema migrations",
+	Long: `Show and apply database schema migrations. acc applies pending migrations on startup,
+a backup of the database is saved to the backups directory before the schema changes.`,
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show applied and pending migrations",
+	Args:  cobra.NoArgs,
+	Run:   MigrateStatus,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:     "up [version]",
+	Short:   "Apply pending migrations up to version (default: latest)",
+	Example: `acc migrate up`,
+	Args:    cobra.MaximumNArgs(1),
+	Run:     MigrateUp,
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [version]",
+	Short: "Revert migrations down to version (default: the previous version)",
+	Long: `Revert migrations down to version (default: the previous version). Data of the features
+that are reverted is dropped, a backup of the database is saved first.`,
+	Example: `acc migrate down 6`,
+	Args:    cobra.MaximumNArgs(1),
+	Run:     MigrateDown,
+}
+
+func init() {
+	RootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateStatusCmd, migrateUpCmd, migrateDownCmd)
+}
+
+func MigrateStatus(cmd *cobra.Command, args []string) {
+	version, migrations, err := schemaState()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"Version", "Name", "Status"})
+	for _, m := range migrations {
+		status := "pending"
+		if m.Version <= version {
+			status = "applied"
+		}
+		t.AppendRow(table.Row{m.Version, m.Name, status})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+	fmt.Printf("Schema version: %d of %d\n", version, len(migrations))
+}
+
+func MigrateUp(cmd *cobra.Command, args []string) {
+	version, migrations, err := schemaState()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	target, err := migrationTarget(args, len(migrations), len(migrations))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if target < version {
+		fmt.Printf("Database is already at version %d, use acc migrate down to revert\n", version)
+		return
+	}
+	err = migrateTo(os.Stdout, version, target, migrations, cmd.Flag("dry").Value.String() == "true")
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func MigrateDown(cmd *cobra.Command, args []string) {
+	version, migrations, err := schemaState()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	target, err := migrationTarget(args, version-1, len(migrations))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if target > version {
+		fmt.Printf("Database is at version %d, use acc migrate up to apply migrations\n", version)
+		return
+	}
+	dry := cmd.Flag("dry").Value.String() == "true"
+	if target < version && !dry {
+		fmt.Printf("Reverting to version %d drops the data of the reverted migrations. ", target)
+		if !utils.PromptConfirmation() {
+			fmt.Println("Aborted")
+			return
+		}
+	}
+	err = migrateTo(os.Stdout, version, target, migrations, dry)
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+func schemaState() (int, []database.Migration, error) {
+	migrations, err := database.Migrations()
+	if err != nil {
+		return 0, nil, err
+	}
+	version, err := database.SchemaVersion()
+	if err != nil {
+		return 0, nil, err
+	}
+	if version > len(migrations) {
+		return 0, nil, fmt.Errorf("database schema version %d is newer than this version of acc supports (%d)", version, len(migrations))
+	}
+	return version, migrations, nil
+}
+
+func migrationTarget(args []string, fallback int, latest int) (int, error) {
+	if len(args) == 0 {
+		if fallback < 0 {
+			return 0, errors.New("nothing to revert, the database is at version 0")
+		}
+		return fallback, nil
+	}
+	target, err := strconv.Atoi(args[0])
+	if err != nil || target < 0 || target > latest {
+		return 0, fmt.Errorf("invalid version '%s'. expected 0 to %d", args[0], latest)
+	}
+	return target, nil
+}
+
+// migrateTo lists the migrations it applies on w and backs the database up before the first one
+func migrateTo(w io.Writer, version, target int, migrations []database.Migration, dry bool) error {
+	if version == target {
+		fmt.Fprintf(w, "Database is at version %d, nothing to do\n", version)
+		return nil
+	}
+	for v := version; v != target; {
+		if v < target {
+			fmt.Fprintf(w, "Applying %04d_%s\n", migrations[v].Version, migrations[v].Name)
+			v++
+		} else {
+			fmt.Fprintf(w, "Reverting %04d_%s\n", migrations[v-1].Version, migrations[v-1].Name)
+			v--
+		}
+	}
+	if dry {
+		return nil
+	}
+	path, err := autoBackup(fmt.Sprintf("v%d", version))
+	if err != nil {
+		return fmt.Errorf("failed to back up the database, nothing was migrated: %w", err)
+	}
+	fmt.Fprintln(w, "Backup saved to", path)
+	if err := database.Migrate(target); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Database migrated from version %d to %d\n", version, target)
+	return nil
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var recurringCmd = &cobra.Command{
+	Use:     "recurring",
+	Aliases: []string{"rec"},
+	Short:   "Manage recurring transactions like rent, salary and subscriptions",
+	Long: `Manage recurring transactions like rent, salary and subscriptions. Occurrences that are
+due are posted as transactions whenever acc runs, or with acc recurring run.`,
+}
+
+var recurringAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a recurring transaction, past occurrences since --start are posted right away",
+	Example: `acc recurring add -t expense -d Rent -a 1200 --every month --on 1 --start 2026-01-01
+acc recurring add -t income -d Salary -a 3000 --every month --on last
+acc recurring add -t expense -d Gym -a 15 --every week --interval 2 --on fri`,
+	Run: RecurringAdd,
+}
+
+var recurringLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List recurring transactions and their next occurrence",
+	Run:   RecurringLs,
+}
+
+var recurringRmCmd = &cobra.Command{
+	Use:   "rm <id>",
+	Short: "Remove a recurring transaction, transactions it posted are kept",
+	Args:  cobra.ExactArgs(1),
+	Run:   RecurringRm,
+}
+
+var recurringPauseCmd = &cobra.Command{
+	Use:   "pause <id>",
+	Short: "Stop posting a recurring transaction until it is resumed",
+	Args:  cobra.ExactArgs(1),
+	Run:   RecurringPause,
+}
+
+var recurringResumeCmd = &cobra.Command{
+	Use:   "resume <id>",
+	Short: "Resume a paused recurring transaction, occurrences missed while paused are skipped",
+	Args:  cobra.ExactArgs(1),
+	Run:   RecurringResume,
+}
+
+var recurringRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Post the occurrences that are due",
+	Run:   RecurringRun,
+}
+
+func init() {
+	RootCmd.AddCommand(recurringCmd)
+	recurringCmd.AddCommand(recurringAddCmd, recurringLsCmd, recurringRmCmd, recurringPauseCmd, recurringResumeCmd, recurringRunCmd)
+	recurringAddCmd.Flags().StringP("type", "t", "", "Type of transaction (income, expense or transfer)")
+	recurringAddCmd.Flags().StringP("description", "d", "", "Description")
+	recurringAddCmd.Flags().String("note", "", "Longer note, searchable with acc search")
+	recurringAddCmd.Flags().StringP("amount", "a", "", "Amount (e.g. 12.34)")
+	recurringAddCmd.Flags().String("currency", "", "Currency of the amount, e.g. EUR (default: base currency)")
+	recurringAddCmd.Flags().String("account", "", "Account the transactions are booked on (default: default)")
+	recurringAddCmd.Flags().String("to", "", "Destination account of a transfer")
+	recurringAddCmd.Flags().StringP("category", "c", "", "Category path (e.g. \"Housing > Rent\")")
+	recurringAddCmd.Flags().StringSlice("tag", []string{}, "Tag the transactions, can be repeated")
+	recurringAddCmd.Flags().String("every", "", "Repeat every day, week, month or year")
+	recurringAddCmd.Flags().Int("interval", 1, "Repeat every n days, weeks, months or years")
+	recurringAddCmd.Flags().String("on", "", "Day of the month (1-31 or last) or weekday (mon-sun) (default: the day of --start)")
+	recurringAddCmd.Flags().String("start", "", "Date of the first occurrence (e.g. 2026-01-01) (default: today)")
+	recurringAddCmd.Flags().String("end", "", "Date after which nothing is posted")
+	recurringAddCmd.MarkFlagRequired("type")
+	recurringAddCmd.MarkFlagRequired("description")
+	recurringAddCmd.MarkFlagRequired("amount")
+	recurringAddCmd.MarkFlagRequired("every")
+}
+
+func RecurringAdd(cmd *cobra.Command, args []string) {
+	transactionType, _ := cmd.Flags().GetString("type")
+	if transactionType != "income" && transactionType != "expense" && transactionType != "transfer" {
+		fmt.Println("Supported transaction types: income, expense, transfer")
+		return
+	}
+	rawAmount, _ := cmd.Flags().GetString("amount")
+	amount, err := money.Parse(rawAmount)
+	if err != nil {
+		fmt.Println(utils.AmountSyntaxError)
+		return
+	}
+	schedule, err := scheduleFromFlags(cmd)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	accountID, toAccountID, err := resolveAccounts(cmd, transactionType)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var categoryID *int
+	if category, _ := cmd.Flags().GetString("category"); category != "" {
+		id, err := database.NewCategoryRepository().FindCategory(category)
+		if err != nil {
+			fmt.Println(err)
+			fmt.Println("Create it first with: acc category add \"" + category + "\"")
+			return
+		}
+		categoryID = &id
+	}
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	if err := list.ValidateTags(tags, false); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for i, tag := range tags {
+		tags[i] = database.NormalizeTag(tag)
+	}
+	currency, _ := cmd.Flags().GetString("currency")
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency, err = database.NewFxRepository().GetBaseCurrency()
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if err := list.ValidateCurrency(currency); err != nil {
+		fmt.Println(err)
+		return
+	}
+	description, _ := cmd.Flags().GetString("description")
+	note, _ := cmd.Flags().GetString("note")
+	rule := database.RecurringRule{
+		Type:        transactionType,
+		Description: description,
+		Note:        note,
+		Amount:      amount,
+		Currency:    currency,
+		CategoryID:  categoryID,
+		AccountID:   accountID,
+		ToAccountID: toAccountID,
+		Tags:        strings.Join(tags, ","),
+		Schedule:    schedule,
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Printf("Would add %s %s for %s %s %s\n", transactionType, description, amount, currency, schedule)
+		return
+	}
+	repo := database.NewRecurringRepository()
+	id, err := repo.CreateRule(rule)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Added recurring %s %d: %s for %s %s %s\n", transactionType, id, description, amount, currency, schedule)
+	postRecurring(repo, os.Stdout)
+}
+
+func scheduleFromFlags(cmd *cobra.Command) (database.Schedule, error) {
+	every, _ := cmd.Flags().GetString("every")
+	interval, _ := cmd.Flags().GetInt("interval")
+	on, _ := cmd.Flags().GetString("on")
+	dates := map[string]string{"start": today(), "end": ""}
+	for _, flag := range []string{"start", "end"} {
+		value, _ := cmd.Flags().GetString(flag)
+		if value == "" {
+			continue
+		}
+		t, err := utils.ParseDate(value)
+		if err != nil {
+			return database.Schedule{}, err
+		}
+		dates[flag] = t.Format(database.ScheduleDateFormat)
+	}
+	return database.NewSchedule(every, interval, on, dates["start"], dates["end"])
+}
+
+func RecurringLs(cmd *cobra.Command, args []string) {
+	rules, err := database.NewRecurringRepository().GetRules()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"ID", "Type", "Amt", "Cur", "Desc", "Schedule", "Next", "Account", "Status"})
+	for _, rule := range rules {
+		after := ""
+		if rule.LastDate != nil {
+			after = *rule.LastDate
+		}
+		next := rule.Next(after)
+		status := "active"
+		switch {
+		case rule.Paused:
+			status = "paused"
+		case next == "":
+			status = "ended"
+		}
+		account := rule.Account
+		if rule.ToAccount != "" {
+			account += " -> " + rule.ToAccount
+		}
+		t.AppendRow(table.Row{
+			rule.ID,
+			rule.Type,
+			rule.Amount.String(),
+			rule.Currency,
+			rule.Description,
+			rule.Schedule.String(),
+			next,
+			account,
+			status,
+		})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
+
+func RecurringRm(cmd *cobra.Command, args []string) {
+	id, err := ruleID(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := database.NewRecurringRepository().DeleteRule(id); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func RecurringPause(cmd *cobra.Command, args []string) {
+	id, err := ruleID(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := database.NewRecurringRepository().PauseRule(id); err != nil {
+		fmt.Println(err)
+	}
+}
+
+func RecurringResume(cmd *cobra.Command, args []string) {
+	id, err := ruleID(args[0])
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(database.ScheduleDateFormat)
+	repo := database.NewRecurringRepository()
+	if err := repo.ResumeRule(id, yesterday); err != nil {
+		fmt.Println(err)
+		return
+	}
+	postRecurring(repo, os.Stdout)
+}
+
+func RecurringRun(cmd *cobra.Command, args []string) {
+	repo := database.NewRecurringRepository()
+	if cmd.Flag("dry").Value.String() == "true" {
+		due, err := repo.DueTransactions(today())
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		list.PreviewRenderer(due)
+		return
+	}
+	posted, err := repo.Post(today())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Posted %d recurring transactions\n", posted)
+}
+
+// postRecurring books the occurrences that became due and reports them on w
+func postRecurring(repo database.RecurringRepository, w io.Writer) {
+	posted, err := repo.AutoPost(today())
+	if err != nil {
+		fmt.Fprintln(w, "failed to post recurring transactions:", err)
+		return
+	}
+	if posted > 0 {
+		fmt.Fprintf(w, "Posted %d recurring transactions\n", posted)
+	}
+}
+
+func ruleID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid id '%s'", arg)
+	}
+	return id, nil
+}
+
+// today is the date occurrences are posted through, in UTC like the rest of acc
+func today() string {
+	return time.Now().UTC().Format(database.ScheduleDateFormat)
+}
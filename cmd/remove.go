@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/elliot40404/acc/pkg/database"
 	"github.com/elliot40404/acc/pkg/utils"
@@ -10,7 +11,7 @@ import (
 
 var removeCmd = &cobra.Command{
 	Use:   "rm",
-	Short: "Remove transactions by id or all transactions",
+	Short: "Move transactions to the trash by id or all transactions, see acc trash",
 	Run:   Remove,
 }
 
@@ -36,7 +37,15 @@ func Remove(cmd *cobra.Command, args []string) {
 		fmt.Printf("%+v\n", dc)
 	}
 	if utils.PromptConfirmation() {
-		err := db.DeleteTransactions(dc)
+		if dc.All && !dc.Dry {
+			path, err := autoBackup("rm-all")
+			if err != nil {
+				fmt.Println("failed to back up the database, nothing was removed:", err)
+				return
+			}
+			fmt.Fprintln(os.Stderr, "Backup saved to", path)
+		}
+		_, err := db.DeleteTransactions(dc)
 		if err != nil {
 			panic(err)
 		}
@@ -45,5 +54,4 @@ func Remove(cmd *cobra.Command, args []string) {
 		fmt.Println("Aborted")
 	}
 	fmt.Println("Please specify an id or use --all to remove all transactions")
-	// TODO: maybe have a backup option
 }
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/elliot40404/acc/cmd/config"
+	"github.com/elliot40404/acc/pkg/database"
 	"github.com/elliot40404/acc/pkg/utils"
 	"github.com/spf13/cobra"
 )
@@ -25,18 +29,100 @@ func init() {
 	RootCmd.PersistentFlags().Bool("verbose", false, "Prints debug messages")
 	RootCmd.PersistentFlags().Bool("trace", false, "Prints trace messages")
 	RootCmd.PersistentFlags().Bool("dry", false, "Dry run")
+	RootCmd.PersistentFlags().String("ledger", "", "Use this ledger instead of the one picked with acc ledger use")
+	RootCmd.PersistentFlags().String("db", "", "Use the database at this path, its backups are kept in the app directory (default: $ACC_DB or the database of the ledger in use)")
 	RootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
-		if cmd.Name() != "init" {
-			checkInitialized()
+		// acc config only reads and writes the config file
+		if cmd.Parent() == configCmd {
+			return
+		}
+		if err := applyConfig(cmd); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := selectDatabase(cmd); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		// acc init creates the database, acc ledger manages the databases of all ledgers
+		if cmd.Name() == "init" || cmd == ledgerCmd || cmd.Parent() == ledgerCmd {
+			return
+		}
+		checkInitialized(cmd)
+		// acc migrate manages the schema version itself
+		if cmd == migrateCmd || cmd.Parent() == migrateCmd {
+			return
+		}
+		upgradeDatabase()
+		// undo, redo and restore should not be preceded by a fresh posting of recurring transactions
+		if cmd != recurringRunCmd && cmd != undoCmd && cmd != redoCmd && cmd != restoreCmd && cmd.Flag("dry").Value.String() != "true" {
+			postRecurring(database.NewRecurringRepository(), os.Stderr)
 		}
 	}
 	// TODO: I should be able to see the TRACES in debug mode
 }
 
-func checkInitialized() {
-	if !utils.IsInitialized() {
+// applyConfig fills in the flags not given on the command line and the display and calendar
+// preferences from the environment and the config file
+func applyConfig(cmd *cobra.Command) error {
+	c, err := config.Load(config.Path())
+	if err != nil {
+		return err
+	}
+	return c.Apply(cmd)
+}
+
+// selectDatabase points the database package at the database picked with --db, --ledger,
+// $ACC_DB or acc ledger use, in that order
+func selectDatabase(cmd *cobra.Command) error {
+	// cobra checks flag groups only after the pre run hooks
+	if cmd.Flags().Changed("db") && cmd.Flags().Changed("ledger") {
+		return errors.New("--ledger and --db cannot be used together")
+	}
+	if path := cmd.Flag("db").Value.String(); path != "" {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		database.DBPATH = abs
+		return nil
+	}
+	if name := cmd.Flag("ledger").Value.String(); name != "" {
+		if err := utils.ValidateLedgerName(name); err != nil {
+			return err
+		}
+		database.DBPATH = utils.LedgerPath(name)
+		return nil
+	}
+	database.DBPATH = utils.DBPATH()
+	return nil
+}
+
+func checkInitialized(cmd *cobra.Command) {
+	if utils.IsInitialized(database.DBPATH) {
+		return
+	}
+	switch {
+	case cmd.Flag("db").Value.String() != "" || os.Getenv("ACC_DB") != "":
+		fmt.Printf("%s does not exist. Run 'acc init' to create it\n", database.DBPATH)
+	case database.DBPATH != utils.LedgerPath(utils.DefaultLedger):
+		name := filepath.Base(filepath.Dir(database.DBPATH))
+		fmt.Printf("ledger %s does not exist. Run 'acc ledger create %s' to create it\n", name, name)
+	default:
 		fmt.Println("acc has not been initialized. Run 'acc init' to initialize the application")
-		os.Exit(0)
 	}
+	os.Exit(0)
+}
 
+// upgradeDatabase applies pending migrations, notices go to stderr to keep the output of
+// the command itself clean
+func upgradeDatabase() {
+	version, migrations, err := schemaState()
+	if err == nil && version < len(migrations) {
+		err = migrateTo(os.Stderr, version, len(migrations), migrations, false)
+	}
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 }
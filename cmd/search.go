@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Full-text search in descriptions and notes, best matches first",
+	Long: `Search descriptions and notes. Words match as prefixes, "quoted phrases" match as written,
+AND, OR, NOT (upper case) and parentheses combine words and a leading - excludes a word.
+Quote the query when it excludes words so they are not read as flags. Accepts the filters
+of acc list.`,
+	Example: `acc search 'coffee OR tea -starbucks'
+acc search '"rent march"' -d thisyear`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  Search,
+}
+
+func init() {
+	RootCmd.AddCommand(searchCmd)
+	// list.go is initialized first, the copies share their values with the list flags
+	listCmd.Flags().VisitAll(func(f *pflag.Flag) {
+		flag := *f
+		flag.Hidden = f.Name == "search"
+		searchCmd.Flags().AddFlag(&flag)
+	})
+}
+
+func Search(cmd *cobra.Command, args []string) {
+	cmd.Flags().Set("search", strings.Join(args, " "))
+	if !cmd.Flags().Changed("sort") {
+		cmd.Flags().Set("sort", "relevance")
+	}
+	List(cmd, args)
+}
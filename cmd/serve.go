@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/server"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve transactions and stats as JSON over HTTP",
+	Long: `Serve transactions and stats of the database in use as JSON over HTTP. Requests need
+the token as a bearer token (Authorization: Bearer <token>). The token is taken from --token
+or $ACC_TOKEN, otherwise a new one is printed at startup. The API is described by the OpenAPI
+document at /openapi.json. Pages of transactions hold --limit transactions unless a request
+sets a limit, list.limit of acc config sets it like it does for acc list.`,
+	Example: `acc serve
+ACC_TOKEN=secret acc serve --addr 127.0.0.1:9000
+curl -H "Authorization: Bearer secret" "http://127.0.0.1:9000/transactions?date=thismonth&type=expense"`,
+	Args: cobra.NoArgs,
+	Run:  Serve,
+}
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("addr", "127.0.0.1:8080", "address to listen on")
+	serveCmd.Flags().String("token", "", "token requests must send (default: $ACC_TOKEN or a new random token)")
+	serveCmd.Flags().Int("limit", 10, "transactions per page when a request sets no limit")
+}
+
+func Serve(cmd *cobra.Command, args []string) {
+	addr, _ := cmd.Flags().GetString("addr")
+	if cmd.Flag("dry").Value.String() == "true" {
+		fmt.Println("Would serve the database on", addr)
+		return
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit < 1 {
+		fmt.Println("invalid limit. limit must be greater than 0")
+		return
+	}
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = os.Getenv("ACC_TOKEN")
+	}
+	if token == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			fmt.Println(err)
+			return
+		}
+		token = hex.EncodeToString(b)
+		fmt.Println("Token:", token)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			fmt.Fprintln(os.Stderr, "Listening beyond this machine, the token is sent unencrypted over plain HTTP")
+		}
+	}
+	srv := &http.Server{Handler: server.New(token, limit), ReadHeaderTimeout: 10 * time.Second}
+	// stop on ctrl+c, letting requests in flight finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+		close(done)
+	}()
+	fmt.Printf("Serving on http://%s, API described at http://%s/openapi.json\n", listener.Addr(), listener.Addr())
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Println(err)
+		return
+	}
+	<-done
+	fmt.Println("Stopped")
+}
@@ -0,0 +1,476 @@
+package server
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/cmd/stats"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Server answers the JSON API described in openapi.json for the database in use. Every
+// request but the one for the OpenAPI document needs the token as a bearer token.
+type Server struct {
+	token string
+	// limit is the page size of GET /transactions without a limit parameter
+	limit int
+	// requests are handled one at a time, SQLite allows a single writer
+	mu sync.Mutex
+}
+
+func New(token string, limit int) *Server {
+	return &Server{token: token, limit: limit}
+}
+
+// maxBodySize is the largest request body read, larger ones are answered with 413
+const maxBodySize = 1 << 20
+
+// requestError is answered with its status, other errors with 500
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e requestError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(err error) error {
+	return requestError{http.StatusBadRequest, err}
+}
+
+func notFound(err error) error {
+	return requestError{http.StatusNotFound, err}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/openapi.json" {
+		if r.Method != http.MethodGet {
+			writeError(w, r, methodNotAllowed(w, http.MethodGet))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeError(w, r, requestError{http.StatusUnauthorized, errors.New("missing or invalid token")})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeError(w, r, s.route(w, r))
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) error {
+	switch {
+	case r.URL.Path == "/transactions":
+		switch r.Method {
+		case http.MethodGet:
+			return listTransactions(w, r, s.limit)
+		case http.MethodPost:
+			return createTransaction(w, r)
+		}
+		return methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	case strings.HasPrefix(r.URL.Path, "/transactions/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/transactions/"))
+		if err != nil || id < 1 {
+			return notFound(errors.New("not found"))
+		}
+		switch r.Method {
+		case http.MethodPatch:
+			return updateTransaction(w, r, id)
+		case http.MethodDelete:
+			return deleteTransaction(w, id)
+		}
+		return methodNotAllowed(w, http.MethodPatch, http.MethodDelete)
+	case r.URL.Path == "/stats":
+		if r.Method != http.MethodGet {
+			return methodNotAllowed(w, http.MethodGet)
+		}
+		return getStats(w, r, s.limit)
+	}
+	return notFound(errors.New("not found"))
+}
+
+func methodNotAllowed(w http.ResponseWriter, methods ...string) error {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	return requestError{http.StatusMethodNotAllowed, errors.New("method not allowed")}
+}
+
+// TransactionList is the response of GET /transactions, Total counts all matching
+// transactions across pages
+type TransactionList struct {
+	Transactions []database.Transaction `json:"transactions"`
+	Page         int                    `json:"page"`
+	Limit        int                    `json:"limit"`
+	Total        int                    `json:"total"`
+}
+
+func listTransactions(w http.ResponseWriter, r *http.Request, limit int) error {
+	queryConfig, err := transactionConfig(r, limit)
+	if err != nil {
+		return err
+	}
+	repo := database.NewTransactionRepository()
+	total, err := repo.GetTransactionCountWithConfig(queryConfig)
+	if err != nil {
+		return err
+	}
+	transactions, err := repo.GetTransactionsWithConfig(queryConfig)
+	if err != nil {
+		return err
+	}
+	if transactions == nil {
+		transactions = []database.Transaction{}
+	}
+	return writeJSON(w, http.StatusOK, TransactionList{
+		Transactions: transactions,
+		Page:         queryConfig.Page,
+		Limit:        queryConfig.Limit,
+		Total:        total,
+	})
+}
+
+// StatsResponse is the response of GET /stats, Skipped counts the transactions left out
+// for lack of an exchange rate to the base currency
+type StatsResponse struct {
+	stats.Report
+	Skipped int `json:"skipped"`
+}
+
+func getStats(w http.ResponseWriter, r *http.Request, limit int) error {
+	queryConfig, err := transactionConfig(r, limit)
+	if err != nil {
+		return err
+	}
+	queryConfig.All = true
+	query := r.URL.Query()
+	group := query.Get("group")
+	if group == "" {
+		group = "month"
+	}
+	if err := stats.ValidateGroup(group); err != nil {
+		return badRequest(err)
+	}
+	depth, err := intParam(query.Get("depth"), "depth", 0)
+	if err != nil {
+		return err
+	}
+	report, missing, err := stats.Generate(queryConfig, group, depth)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, StatsResponse{Report: report, Skipped: missing})
+}
+
+// transactionConfig reads the filters of GET /transactions and GET /stats, they are named
+// like the flags of acc list. limit is used when the request sets none
+func transactionConfig(r *http.Request, limit int) (database.TransactionConfig, error) {
+	query := r.URL.Query()
+	queryConfig := database.TransactionConfig{
+		TxType:   query.Get("type"),
+		Date:     query.Get("date"),
+		Amount:   query.Get("amount"),
+		Sort:     query.Get("sort"),
+		Desc:     query.Get("desc"),
+		Search:   query.Get("search"),
+		Category: query.Get("category"),
+		Tags:     query["tag"],
+		TagMode:  query.Get("tag-mode"),
+		Account:  query.Get("account"),
+		Currency: strings.ToUpper(query.Get("currency")),
+	}
+	var err error
+	if queryConfig.Page, err = intParam(query.Get("page"), "page", 1); err != nil {
+		return queryConfig, err
+	}
+	if queryConfig.Limit, err = intParam(query.Get("limit"), "limit", limit); err != nil {
+		return queryConfig, err
+	}
+	if queryConfig.All, err = boolParam(query.Get("all"), "all"); err != nil {
+		return queryConfig, err
+	}
+	if queryConfig.SortAsc, err = boolParam(query.Get("asc"), "asc"); err != nil {
+		return queryConfig, err
+	}
+	if queryConfig.IncludeDeleted, err = boolParam(query.Get("include-deleted"), "include-deleted"); err != nil {
+		return queryConfig, err
+	}
+	if err := list.ValidateConfig(&queryConfig); err != nil {
+		return queryConfig, badRequest(err)
+	}
+	if queryConfig.Category != "" {
+		if queryConfig.CategoryID, err = database.NewCategoryRepository().FindCategory(queryConfig.Category); err != nil {
+			return queryConfig, badRequest(err)
+		}
+	}
+	if queryConfig.Account != "" {
+		if queryConfig.AccountID, err = database.NewAccountRepository().FindAccount(queryConfig.Account); err != nil {
+			return queryConfig, badRequest(err)
+		}
+	}
+	return queryConfig, nil
+}
+
+func intParam(value string, name string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, badRequest(fmt.Errorf("invalid %s '%s'. %s must be a number", name, value, name))
+	}
+	return n, nil
+}
+
+func boolParam(value string, name string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, badRequest(fmt.Errorf("invalid %s '%s'. expected true or false", name, value))
+	}
+	return b, nil
+}
+
+// TransactionInput is the body of POST /transactions and PATCH /transactions/{id}. Fields
+// are named like the flags of acc add and acc edit, PATCH leaves absent fields untouched.
+type TransactionInput struct {
+	Type        *string      `json:"type"`
+	Description *string      `json:"description"`
+	Note        *string      `json:"note"`
+	Amount      *money.Money `json:"amount"`
+	Currency    *string      `json:"currency"`
+	Date        *string      `json:"date"`
+	Category    *string      `json:"category"`
+	Account     *string      `json:"account"`
+	To          *string      `json:"to"`
+	// Tags of a PATCH are added, prefixed with ! they are removed
+	Tags []string `json:"tags"`
+}
+
+func readInput(w http.ResponseWriter, r *http.Request) (TransactionInput, error) {
+	var input TransactionInput
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return input, requestError{http.StatusRequestEntityTooLarge, fmt.Errorf("request body larger than %d bytes", tooLarge.Limit)}
+		}
+		return input, badRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+	if input.Type != nil {
+		if err := validateType(*input.Type); err != nil {
+			return input, err
+		}
+	}
+	if input.Currency != nil {
+		currency := strings.ToUpper(*input.Currency)
+		if err := list.ValidateCurrency(currency); err != nil {
+			return input, badRequest(err)
+		}
+		input.Currency = &currency
+	}
+	if input.Date != nil {
+		t, err := utils.ParseDate(*input.Date)
+		if err != nil {
+			return input, badRequest(err)
+		}
+		date := t.Format(utils.DBTimeFormat)
+		input.Date = &date
+	}
+	return input, nil
+}
+
+func validateType(transactionType string) error {
+	if transactionType != "income" && transactionType != "expense" && transactionType != "transfer" {
+		return badRequest(errors.New("invalid type. type must be one of income, expense or transfer"))
+	}
+	return nil
+}
+
+func createTransaction(w http.ResponseWriter, r *http.Request) error {
+	input, err := readInput(w, r)
+	if err != nil {
+		return err
+	}
+	if input.Type == nil || input.Description == nil || input.Amount == nil {
+		return badRequest(errors.New("type, description and amount are required"))
+	}
+	transaction := database.Transaction{
+		Type:        *input.Type,
+		Description: *input.Description,
+		Amount:      *input.Amount,
+		AccountID:   database.DefaultAccountID,
+	}
+	if input.Note != nil {
+		transaction.Note = *input.Note
+	}
+	if input.Date != nil {
+		transaction.OccurredAt = *input.Date
+	}
+	accounts := database.NewAccountRepository()
+	if input.Account != nil {
+		if transaction.AccountID, err = accounts.FindAccount(*input.Account); err != nil {
+			return badRequest(err)
+		}
+	}
+	switch {
+	case transaction.Type != "transfer" && input.To != nil:
+		return badRequest(errors.New("to can only be used with transfers"))
+	case transaction.Type == "transfer" && input.To == nil:
+		return badRequest(errors.New("transfers need a destination account, set to"))
+	case input.To != nil:
+		id, err := accounts.FindAccount(*input.To)
+		if err != nil {
+			return badRequest(err)
+		}
+		if id == transaction.AccountID {
+			return badRequest(errors.New("cannot transfer to the same account"))
+		}
+		transaction.ToAccountID = &id
+	}
+	if input.Category != nil {
+		id, err := database.NewCategoryRepository().FindCategory(*input.Category)
+		if err != nil {
+			return badRequest(err)
+		}
+		transaction.CategoryID = &id
+	}
+	if err := list.ValidateTags(input.Tags, false); err != nil {
+		return badRequest(err)
+	}
+	transaction.Tags = input.Tags
+	if input.Currency != nil {
+		transaction.Currency = *input.Currency
+	} else if transaction.Currency, err = database.NewFxRepository().GetBaseCurrency(); err != nil {
+		return err
+	}
+	id, err := database.NewTransactionRepository().CreateTransaction(transaction)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", fmt.Sprintf("/transactions/%d", id))
+	return writeJSON(w, http.StatusCreated, map[string]int{"id": id})
+}
+
+func updateTransaction(w http.ResponseWriter, r *http.Request, id int) error {
+	input, err := readInput(w, r)
+	if err != nil {
+		return err
+	}
+	uc := database.UpdateConfig{
+		ID:          id,
+		Type:        input.Type,
+		Description: input.Description,
+		Note:        input.Note,
+		Amount:      input.Amount,
+		Currency:    input.Currency,
+		Date:        input.Date,
+	}
+	if input.Category != nil {
+		categoryID, err := database.NewCategoryRepository().FindCategory(*input.Category)
+		if err != nil {
+			return badRequest(err)
+		}
+		uc.CategoryID = &categoryID
+	}
+	accounts := database.NewAccountRepository()
+	if input.Account != nil {
+		accountID, err := accounts.FindAccount(*input.Account)
+		if err != nil {
+			return badRequest(err)
+		}
+		uc.AccountID = &accountID
+	}
+	if input.To != nil {
+		toAccountID, err := accounts.FindAccount(*input.To)
+		if err != nil {
+			return badRequest(err)
+		}
+		uc.ToAccountID = &toAccountID
+	}
+	if err := list.ValidateTags(input.Tags, true); err != nil {
+		return badRequest(err)
+	}
+	uc.AddTags, uc.RemoveTags = database.SplitTagFilter(input.Tags)
+	if uc.Type == nil && uc.Description == nil && uc.Note == nil && uc.Amount == nil && uc.Currency == nil && uc.Date == nil && uc.CategoryID == nil &&
+		uc.AccountID == nil && uc.ToAccountID == nil && len(input.Tags) == 0 {
+		return badRequest(errors.New("nothing to update"))
+	}
+	err = database.NewTransactionRepository().UpdateTransaction(uc)
+	if errors.Is(err, database.ErrNotFound) {
+		return notFound(err)
+	}
+	if errors.Is(err, database.ErrInvalid) {
+		return badRequest(err)
+	}
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// deleteTransaction moves the transaction to the trash, like acc rm
+func deleteTransaction(w http.ResponseWriter, id int) error {
+	deleted, err := database.NewTransactionRepository().DeleteTransactions(database.DeleteConfig{Ids: []string{strconv.Itoa(id)}})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return notFound(fmt.Errorf("transaction %d not found", id))
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(b, '\n'))
+	return nil
+}
+
+// writeError answers err as {"error": "..."}, other errors than requestError are logged and
+// answered as an internal error. nothing is written for a nil err
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+	var re requestError
+	if !errors.As(err, &re) {
+		// database and driver errors are for the log, not for clients
+		slog.Error("API: request failed", "Method", r.Method, "Path", r.URL.Path, "Error", err.Error())
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	writeJSON(w, re.status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,164 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/server"
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func request(t *testing.T, handler http.Handler, method string, target string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestServer(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	s := server.New("secret", 10)
+
+	r := httptest.NewRequest(http.MethodGet, "/transactions", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Error("expected 401 for a wrong token, got", w.Code)
+	}
+
+	w = request(t, s, http.MethodPost, "/transactions", `{"type": "expense", "description": "coffee", "amount": 3.5, "tags": ["trip"]}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/transactions/1" {
+		t.Fatal("expected transaction 1 to be created, got", w.Code, w.Body.String())
+	}
+	request(t, s, http.MethodPost, "/transactions", `{"type": "income", "description": "salary", "amount": "1000", "date": "2024-01-31"}`)
+	if w = request(t, s, http.MethodPost, "/transactions", `{"type": "gift", "description": "x", "amount": 1}`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for an invalid type, got", w.Code)
+	}
+	if w = request(t, s, http.MethodPost, "/transactions", `{"type": "transfer", "description": "x", "amount": 1}`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for a transfer without a destination, got", w.Code)
+	}
+
+	w = request(t, s, http.MethodGet, "/transactions?type=expense&tag=trip", "")
+	var list server.TransactionList
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err, w.Body.String())
+	}
+	if list.Total != 1 || len(list.Transactions) != 1 || list.Transactions[0].Description != "coffee" {
+		t.Error("expected the coffee expense, got", w.Body.String())
+	}
+	// the page size of acc serve --limit applies unless the request sets one
+	w = request(t, server.New("secret", 1), http.MethodGet, "/transactions", "")
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err, w.Body.String())
+	}
+	if list.Limit != 1 || len(list.Transactions) != 1 || list.Total != 2 {
+		t.Error("expected a page of 1 out of 2 transactions, got", w.Body.String())
+	}
+	if w = request(t, s, http.MethodGet, "/transactions?page=0", ""); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for page 0, got", w.Code)
+	}
+	if w = request(t, s, http.MethodGet, "/transactions?sort=name", ""); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for an invalid sort, got", w.Code)
+	}
+
+	if w = request(t, s, http.MethodPatch, "/transactions/1", `{"amount": "4.20", "tags": ["!trip"]}`); w.Code != http.StatusNoContent {
+		t.Error("expected 204, got", w.Code, w.Body.String())
+	}
+	if w = request(t, s, http.MethodPatch, "/transactions/9", `{"amount": 1}`); w.Code != http.StatusNotFound {
+		t.Error("expected 404 for a missing transaction, got", w.Code)
+	}
+	if w = request(t, s, http.MethodPatch, "/transactions/1", `{"note": "`+strings.Repeat("x", 1<<20)+`"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Error("expected 413 for a body over 1 MiB, got", w.Code)
+	}
+	if w = request(t, s, http.MethodPatch, "/transactions/1", `{}`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for an empty update, got", w.Code)
+	}
+	if w = request(t, s, http.MethodPatch, "/transactions/1", `{"type": "transfer"}`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for a transfer without a destination, got", w.Code, w.Body.String())
+	}
+	if w = request(t, s, http.MethodPatch, "/transactions/1", `{"to": "default"}`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for a destination of an expense, got", w.Code, w.Body.String())
+	}
+	if w = request(t, s, http.MethodPatch, "/transactions/1", `{"type": "transfer", "to": "default"}`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for a transfer to the same account, got", w.Code, w.Body.String())
+	}
+	if w = request(t, s, http.MethodGet, "/transactions?tag=trip", ""); !strings.Contains(w.Body.String(), `"total":0`) {
+		t.Error("expected the tag to be removed, got", w.Body.String())
+	}
+
+	w = request(t, s, http.MethodGet, "/stats?group=year&all=true", "")
+	var stats server.StatsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err, w.Body.String())
+	}
+	if stats.Total.Count != 2 || stats.Total.Expense.String() != "4.20" || stats.Total.Income.String() != "1000.00" {
+		t.Error("expected 2 transactions, 4.20 expense and 1000.00 income, got", w.Body.String())
+	}
+
+	if w = request(t, s, http.MethodDelete, "/transactions/1", ""); w.Code != http.StatusNoContent {
+		t.Error("expected 204, got", w.Code)
+	}
+	if w = request(t, s, http.MethodDelete, "/transactions/1", ""); w.Code != http.StatusNotFound {
+		t.Error("expected 404 for a transaction in the trash, got", w.Code)
+	}
+	if w = request(t, s, http.MethodPut, "/transactions/2", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Error("expected 405, got", w.Code)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	w := httptest.NewRecorder()
+	server.New("secret", 10).ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatal("expected the document without a token, got", w.Code)
+	}
+	var doc struct {
+		Paths map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	for path, methods := range map[string][]string{
+		"/transactions":      {"get", "post"},
+		"/transactions/{id}": {"patch", "delete"},
+		"/stats":             {"get"},
+	} {
+		for _, method := range methods {
+			if doc.Paths[path][method] == nil {
+				t.Error("expected", method, path, "to be documented")
+			}
+		}
+	}
+}
+
+func TestInternalError(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("DROP TABLE transactions"); err != nil {
+		t.Fatal(err)
+	}
+	w := request(t, server.New("secret", 10), http.MethodGet, "/transactions", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatal("expected 500, got", w.Code, w.Body.String())
+	}
+	if strings.TrimSpace(w.Body.String()) != `{"error":"internal error"}` {
+		t.Error("expected the database error to stay on the server, got", w.Body.String())
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/elliot40404/acc/cmd/shell"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Start an interactive acc shell",
+	Run:   Shell,
+}
+
+func init() {
+	RootCmd.AddCommand(shellCmd)
+}
+
+func Shell(cmd *cobra.Command, args []string) {
+	// the shell resets flags before every command, keep using the database picked for it
+	if cmd.Flags().Changed("db") || cmd.Flags().Changed("ledger") {
+		os.Setenv("ACC_DB", database.DBPATH)
+	}
+	if err := shell.New(RootCmd).Run(); err != nil {
+		fmt.Println(err)
+	}
+}
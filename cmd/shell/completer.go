@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var builtins = []string{
+	"exit",
+	"help",
+	"set",
+	"unset",
+}
+
+// complete returns the completed line along with all candidates for the last word
+func (s *Shell) complete(line string) (string, []string) {
+	words := strings.Fields(line)
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	last := words[len(words)-1]
+	var candidates []string
+	switch {
+	case len(words) == 1:
+		candidates = s.commandNames()
+	case words[0] == "set" && len(words) == 2:
+		candidates = s.stickyNames()
+	case strings.HasPrefix(last, "-"):
+		if c, _, err := s.root.Find(words[:1]); err == nil && c != s.root {
+			candidates = flagNames(c)
+		}
+	case words[0] == "set" && words[1] == "date", s.isDateFlag(words[0], words[len(words)-2]):
+		candidates = utils.ValidDateBuiltins
+	}
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, last) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return line, nil
+	}
+	completed := commonPrefix(matches)
+	if len(matches) == 1 {
+		completed += " "
+	}
+	return line[:len(line)-len(last)] + completed, matches
+}
+
+func (s *Shell) commandNames() []string {
+	names := append([]string{}, builtins...)
+	for _, c := range s.root.Commands() {
+		if c.Name() != "shell" && c.IsAvailableCommand() {
+			names = append(names, c.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Shell) stickyNames() []string {
+	seen := map[string]bool{}
+	for _, c := range s.root.Commands() {
+		if !isSticky(c.Name()) {
+			continue
+		}
+		c.Flags().VisitAll(func(f *pflag.Flag) {
+			seen[f.Name] = true
+		})
+	}
+	names := []string{}
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func flagNames(c *cobra.Command) []string {
+	names := []string{}
+	add := func(f *pflag.Flag) {
+		names = append(names, "--"+f.Name)
+	}
+	c.Flags().VisitAll(add)
+	c.Root().PersistentFlags().VisitAll(add)
+	sort.Strings(names)
+	return names
+}
+
+// isDateFlag reports whether word is the --date flag (or its shorthand) of the given command
+func (s *Shell) isDateFlag(command string, word string) bool {
+	c, _, err := s.root.Find([]string{command})
+	if err != nil || c == s.root {
+		return false
+	}
+	var f *pflag.Flag
+	if strings.HasPrefix(word, "--") {
+		f = c.Flags().Lookup(word[2:])
+	} else if len(word) == 2 && word[0] == '-' {
+		f = c.Flags().ShorthandLookup(word[1:])
+	}
+	return f != nil && f.Name == "date"
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,260 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+const prompt = "acc> "
+
+// commands that read sticky filters set via `set <flag> <value>`
+var stickyCommands = []string{
+	"list",
+	"search",
+	"stats",
+}
+
+type Shell struct {
+	root   *cobra.Command
+	sticky map[string]string
+}
+
+func New(root *cobra.Command) *Shell {
+	return &Shell{
+		root:   root,
+		sticky: map[string]string{},
+	}
+}
+
+// Run starts the REPL, it returns when the user exits or stdin is closed
+func (s *Shell) Run() error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return s.runPlain(os.Stdin)
+	}
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, prompt)
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		newLine, candidates := s.complete(line[:pos])
+		if len(candidates) > 1 {
+			fmt.Fprintln(t, strings.Join(candidates, "  "))
+		}
+		return newLine + line[pos:], len(newLine), true
+	}
+	fmt.Println("acc shell. type 'help' for builtins, 'exit' to quit")
+	for {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		if width, height, err := term.GetSize(fd); err == nil {
+			t.SetSize(width, height)
+		}
+		line, err := t.ReadLine()
+		term.Restore(fd, state)
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !s.exec(line) {
+			return nil
+		}
+	}
+}
+
+func (s *Shell) runPlain(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if !s.exec(scanner.Text()) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// exec runs a single line and reports whether the shell should keep going
+func (s *Shell) exec(line string) bool {
+	args, err := splitArgs(line)
+	if err != nil {
+		fmt.Println(err)
+		return true
+	}
+	if len(args) == 0 {
+		return true
+	}
+	switch args[0] {
+	case "exit", "quit":
+		return false
+	case "help":
+		printHelp()
+		return true
+	case "set":
+		s.set(args[1:])
+		return true
+	case "unset":
+		s.unset(args[1:])
+		return true
+	case "shell":
+		fmt.Println("already in a shell")
+		return true
+	}
+	c, _, err := s.root.Find(args)
+	if err != nil || c == s.root {
+		fmt.Printf("unknown command %q. type 'help' for builtins\n", args[0])
+		return true
+	}
+	resetFlags(c)
+	if isSticky(c.Name()) {
+		s.applySticky(c)
+	}
+	s.root.SetArgs(args)
+	s.root.Execute()
+	return true
+}
+
+func (s *Shell) set(args []string) {
+	if len(args) == 0 {
+		keys := make([]string, 0, len(s.sticky))
+		for key := range s.sticky {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Printf("%s = %s\n", key, s.sticky[key])
+		}
+		return
+	}
+	if len(args) != 2 {
+		fmt.Println("usage: set <flag> <value>")
+		return
+	}
+	key := strings.TrimLeft(args[0], "-")
+	if s.stickyFlag(key) == nil {
+		fmt.Printf("unknown filter %q\n", key)
+		return
+	}
+	s.sticky[key] = args[1]
+}
+
+func (s *Shell) unset(args []string) {
+	if len(args) == 0 {
+		s.sticky = map[string]string{}
+		return
+	}
+	for _, arg := range args {
+		delete(s.sticky, strings.TrimLeft(arg, "-"))
+	}
+}
+
+// stickyFlag looks up a flag by name on any of the commands honoring sticky filters
+func (s *Shell) stickyFlag(name string) *pflag.Flag {
+	for _, c := range s.root.Commands() {
+		if !isSticky(c.Name()) {
+			continue
+		}
+		if f := c.Flags().Lookup(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Shell) applySticky(c *cobra.Command) {
+	for key, value := range s.sticky {
+		f := c.Flags().Lookup(key)
+		if f == nil {
+			continue
+		}
+		if err := f.Value.Set(value); err != nil {
+			fmt.Printf("ignoring sticky %s: %s\n", key, err)
+			continue
+		}
+		f.Changed = true
+	}
+}
+
+// resetFlags restores the defaults since cobra keeps flag values between executions
+func resetFlags(c *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			sv.Replace([]string{})
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	c.Flags().VisitAll(reset)
+	c.Root().PersistentFlags().VisitAll(reset)
+}
+
+func isSticky(name string) bool {
+	for _, command := range stickyCommands {
+		if name == command {
+			return true
+		}
+	}
+	return false
+}
+
+// splitArgs splits a line on whitespace honoring single and double quotes
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func printHelp() {
+	fmt.Println("Any acc command can be used without the 'acc' prefix, e.g. list -d today")
+	fmt.Println("Builtins:")
+	fmt.Println("set                - show sticky filters")
+	fmt.Println("set <flag> <value> - apply a filter to every following list/stats (e.g. set date thismonth)")
+	fmt.Println("unset [flag...]    - remove one or all sticky filters")
+	fmt.Println("exit               - leave the shell")
+}
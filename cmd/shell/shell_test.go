@@ -0,0 +1,55 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSplitArgs(t *testing.T) {
+	args, err := splitArgs(`add -t expense -d "Coffee beans" -a 12`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 7 || args[4] != "Coffee beans" {
+		t.Error("expected 7 args with 'Coffee beans', got", args)
+	}
+	args, _ = splitArgs(`list -D ''`)
+	if len(args) != 3 || args[2] != "" {
+		t.Error("expected an empty third arg, got", args)
+	}
+	_, err = splitArgs(`add -d "oops`)
+	if err == nil {
+		t.Error("expected unterminated quote error")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	root := &cobra.Command{Use: "acc"}
+	list := &cobra.Command{Use: "list", Run: func(cmd *cobra.Command, args []string) {}}
+	list.Flags().StringP("date", "d", "", "")
+	list.Flags().StringP("desc", "D", "", "")
+	root.AddCommand(list)
+	s := New(root)
+
+	line, _ := s.complete("li")
+	if line != "list " {
+		t.Errorf("expected 'list ', got %q", line)
+	}
+	line, candidates := s.complete("list --d")
+	if line != "list --d" || len(candidates) != 2 {
+		t.Errorf("expected two candidates, got %q %v", line, candidates)
+	}
+	line, _ = s.complete("list -d yes")
+	if line != "list -d yesterday " {
+		t.Errorf("expected 'list -d yesterday ', got %q", line)
+	}
+	line, _ = s.complete("list -D yes")
+	if line != "list -D yes" {
+		t.Errorf("expected no completion for -D, got %q", line)
+	}
+	line, _ = s.complete("set date this")
+	if line != "set date this" {
+		t.Errorf("expected ambiguous completion, got %q", line)
+	}
+}
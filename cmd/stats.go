@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/cmd/stats"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/spf13/cobra"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show totals, net balance and a per period breakdown",
+	Long: `Show income, expense and net totals and a per period breakdown. Transfers between
+accounts are neither income nor expense and are left out. The average, median and max are of
+the income and expense transactions, the expense ones of the expenses only.`,
+	Example: `acc stats -d thisyear -g month`,
+	Run:     Stats,
+}
+
+func init() {
+	RootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().Bool("pretty", false, "pretty print json")
+	statsCmd.Flags().StringP("date", "d", "", "filter by date or date-ranges")
+	statsCmd.Flags().StringP("type", "t", "", "filter by type (income, expense, transfer)")
+	statsCmd.Flags().String("account", "", "filter by account")
+	statsCmd.Flags().String("currency", "", "only include this currency and report original amounts (default: everything converted to the base currency)")
+	statsCmd.Flags().StringP("amount", "a", "", "filter by amount")
+	statsCmd.Flags().StringP("desc", "D", "", "filter by description")
+	statsCmd.Flags().String("category", "", "filter by category, including its subcategories")
+	statsCmd.Flags().StringSlice("tag", []string{}, "filter by tag, can be repeated. prefix with ! to exclude")
+	statsCmd.Flags().String("tag-mode", "any", "match any or all of the given tags")
+	statsCmd.Flags().StringP("group", "g", "month", "group by week, month, year or category")
+	statsCmd.Flags().Int("depth", 0, "roll categories up to this many levels when grouping by category (default: full path)")
+	statsCmd.Flags().Bool("include-deleted", false, "include transactions in the trash")
+	statsCmd.Flags().StringP("format", "f", "table", "print in table/json/csv format")
+}
+
+func Stats(cmd *cobra.Command, args []string) {
+	queryConfig := database.TransactionConfig{
+		Dry:      cmd.Flag("dry").Value.String() == "true",
+		Verbose:  cmd.Flag("verbose").Value.String() == "true",
+		TxType:   cmd.Flag("type").Value.String(),
+		Page:     1,
+		Limit:    1,
+		All:      true,
+		Date:     cmd.Flag("date").Value.String(),
+		Amount:   cmd.Flag("amount").Value.String(),
+		Desc:     cmd.Flag("desc").Value.String(),
+		Category: cmd.Flag("category").Value.String(),
+		TagMode:  cmd.Flag("tag-mode").Value.String(),
+		Account:  cmd.Flag("account").Value.String(),
+		Currency: strings.ToUpper(cmd.Flag("currency").Value.String()),
+		Format:   cmd.Flag("format").Value.String(),
+		IsPretty: cmd.Flag("pretty").Value.String() == "true",
+	}
+	queryConfig.Tags, _ = cmd.Flags().GetStringSlice("tag")
+	queryConfig.IncludeDeleted, _ = cmd.Flags().GetBool("include-deleted")
+	group := cmd.Flag("group").Value.String()
+	depth, _ := cmd.Flags().GetInt("depth")
+	if queryConfig.Verbose {
+		fmt.Printf("%#v\n", queryConfig)
+	}
+	if err := list.ValidateConfig(&queryConfig); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if queryConfig.Category != "" {
+		id, err := database.NewCategoryRepository().FindCategory(queryConfig.Category)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		queryConfig.CategoryID = id
+	}
+	if queryConfig.Account != "" {
+		id, err := database.NewAccountRepository().FindAccount(queryConfig.Account)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		queryConfig.AccountID = id
+	}
+	if err := stats.ValidateGroup(group); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := stats.ValidateFormat(queryConfig.Format); err != nil {
+		fmt.Println(err)
+		return
+	}
+	stats.Render(queryConfig, group, depth)
+}
@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+func Render(queryConfig database.TransactionConfig, group string, depth int) {
+	report, missing, err := Generate(queryConfig, group, depth)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if queryConfig.Dry {
+		return
+	}
+	switch queryConfig.Format {
+	case "json":
+		jsonWriter(report, queryConfig.IsPretty)
+	case "csv":
+		csvWriter(report)
+	default:
+		tableWriter(report)
+	}
+	if missing > 0 {
+		fmt.Fprintf(os.Stderr, "%d transactions skipped, no exchange rate to %s (see acc fx set)\n", missing, report.Currency)
+	}
+}
+
+// Generate builds the report of the transactions matching queryConfig and returns the
+// number of transactions left out for lack of an exchange rate
+func Generate(queryConfig database.TransactionConfig, group string, depth int) (Report, int, error) {
+	transactions, err := database.NewTransactionRepository().GetTransactionsWithConfig(queryConfig)
+	if err != nil {
+		return Report{}, 0, err
+	}
+	// a single currency filter reports original amounts, otherwise everything is converted
+	currency := queryConfig.Currency
+	missing := 0
+	if currency == "" {
+		currency, err = database.NewFxRepository().GetBaseCurrency()
+		if err != nil {
+			return Report{}, 0, err
+		}
+		transactions, missing = ToBaseCurrency(transactions)
+	}
+	report := BuildReport(transactions, group, depth)
+	report.Currency = currency
+	return report, missing, nil
+}
+
+func jsonWriter(report Report, pretty bool) {
+	var b []byte
+	var err error
+	if pretty {
+		b, err = json.MarshalIndent(report, "", "  ")
+	} else {
+		b, err = json.Marshal(report)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(string(b))
+}
+
+func csvWriter(report Report) {
+	csvWriter := csv.NewWriter(os.Stdout)
+	rows := [][]string{{
+		groupHeader(report.Group),
+		"Count",
+		"Income",
+		"Expense",
+		"Net",
+		"Average",
+		"Median",
+		"Max",
+		"Average expense",
+		"Median expense",
+		"Max expense",
+	}}
+	for _, period := range report.Periods {
+		rows = append(rows, csvRow(period.Period, period.Summary))
+	}
+	rows = append(rows, csvRow("Total", report.Total))
+	csvWriter.WriteAll(rows)
+}
+
+func csvRow(period string, s Summary) []string {
+	return []string{
+		period,
+		strconv.Itoa(s.Count),
+		formatAmount(s.Income),
+		formatAmount(s.Expense),
+		formatAmount(s.Net),
+		formatAmount(s.Average),
+		formatAmount(s.Median),
+		formatAmount(s.Max),
+		formatAmount(s.AverageExpense),
+		formatAmount(s.MedianExpense),
+		formatAmount(s.MaxExpense),
+	}
+}
+
+func tableWriter(report Report) {
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{groupHeader(report.Group), "Count", "Income", "Expense", "Net", "Average", "Median", "Max", "Avg expense", "Median expense", "Max expense"})
+	for _, period := range report.Periods {
+		t.AppendRow(tableRow(period.Period, period.Summary, report.Currency))
+	}
+	t.AppendFooter(tableRow("Total", report.Total, report.Currency))
+	t.SetTitle("Amounts in " + report.Currency)
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
+
+func tableRow(period string, s Summary, currency string) table.Row {
+	return table.Row{
+		period,
+		s.Count,
+		utils.FormatAmount(s.Income, currency),
+		utils.FormatAmount(s.Expense, currency),
+		utils.FormatAmount(s.Net, currency),
+		utils.FormatAmount(s.Average, currency),
+		utils.FormatAmount(s.Median, currency),
+		utils.FormatAmount(s.Max, currency),
+		utils.FormatAmount(s.AverageExpense, currency),
+		utils.FormatAmount(s.MedianExpense, currency),
+		utils.FormatAmount(s.MaxExpense, currency),
+	}
+}
+
+func groupHeader(group string) string {
+	if group == "category" {
+		return "Category"
+	}
+	return "Period"
+}
+
+func formatAmount(amount money.Money) string {
+	return amount.String()
+}
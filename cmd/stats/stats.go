@@ -0,0 +1,180 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/itlightning/dateparse"
+)
+
+var validGroups = []string{
+	"week",
+	"month",
+	"year",
+	"category",
+}
+
+// Summary totals income and expenses, transfers between accounts are left out. Average,
+// median and max are of the income and expense transactions, the Expense ones of the
+// expenses only
+type Summary struct {
+	Count          int         `json:"count"`
+	Income         money.Money `json:"income"`
+	Expense        money.Money `json:"expense"`
+	Net            money.Money `json:"net"`
+	Average        money.Money `json:"average"`
+	Median         money.Money `json:"median"`
+	Max            money.Money `json:"max"`
+	AverageExpense money.Money `json:"average_expense"`
+	MedianExpense  money.Money `json:"median_expense"`
+	MaxExpense     money.Money `json:"max_expense"`
+}
+
+type PeriodSummary struct {
+	Period string `json:"period"`
+	Summary
+}
+
+type Report struct {
+	Currency string          `json:"currency"`
+	Group    string          `json:"group"`
+	Depth    int             `json:"depth,omitempty"`
+	Periods  []PeriodSummary `json:"periods"`
+	Total    Summary         `json:"total"`
+}
+
+// ToBaseCurrency replaces every amount with its base currency value, transactions without
+// a known exchange rate are dropped and counted
+func ToBaseCurrency(transactions []database.Transaction) ([]database.Transaction, int) {
+	converted := make([]database.Transaction, 0, len(transactions))
+	missing := 0
+	for _, transaction := range transactions {
+		if transaction.BaseAmount == nil {
+			missing++
+			continue
+		}
+		transaction.Amount = *transaction.BaseAmount
+		converted = append(converted, transaction)
+	}
+	return converted, missing
+}
+
+func Summarize(transactions []database.Transaction) Summary {
+	var s Summary
+	amounts := make([]money.Money, 0, len(transactions))
+	expenses := make([]money.Money, 0, len(transactions))
+	for _, transaction := range transactions {
+		switch transaction.Type {
+		case "income":
+			s.Income += transaction.Amount
+		case "expense":
+			s.Expense += transaction.Amount
+			expenses = append(expenses, transaction.Amount)
+		default:
+			continue
+		}
+		amounts = append(amounts, transaction.Amount)
+	}
+	s.Count = len(amounts)
+	s.Net = s.Income - s.Expense
+	s.Average, s.Median, s.Max = describe(amounts)
+	s.AverageExpense, s.MedianExpense, s.MaxExpense = describe(expenses)
+	return s
+}
+
+// describe returns the average, median and max of amounts, zero when there are none
+func describe(amounts []money.Money) (money.Money, money.Money, money.Money) {
+	if len(amounts) == 0 {
+		return 0, 0, 0
+	}
+	var sum money.Money
+	for _, amount := range amounts {
+		sum += amount
+	}
+	sort.Slice(amounts, func(i, j int) bool {
+		return amounts[i] < amounts[j]
+	})
+	median := amounts[len(amounts)/2]
+	if len(amounts)%2 == 0 {
+		median = (amounts[len(amounts)/2-1] + median).Div(2)
+	}
+	return sum.Div(int64(len(amounts))), median, amounts[len(amounts)-1]
+}
+
+// BuildReport groups transactions by week, month, year (oldest first) or category and
+// summarizes each group. categories are rolled up to depth levels when depth > 0. transfers
+// are left out, so groups holding only transfers are not reported
+func BuildReport(transactions []database.Transaction, group string, depth int) Report {
+	buckets := map[string][]database.Transaction{}
+	for _, transaction := range transactions {
+		if transaction.Type == "transfer" {
+			continue
+		}
+		var key string
+		if group == "category" {
+			key = categoryKey(transaction.Category, depth)
+		} else {
+			key = periodKey(transaction.OccurredAt, group)
+		}
+		buckets[key] = append(buckets[key], transaction)
+	}
+	keys := make([]string, 0, len(buckets))
+	for key := range buckets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	report := Report{
+		Group:   group,
+		Depth:   depth,
+		Periods: []PeriodSummary{},
+		Total:   Summarize(transactions),
+	}
+	for _, key := range keys {
+		report.Periods = append(report.Periods, PeriodSummary{
+			Period:  key,
+			Summary: Summarize(buckets[key]),
+		})
+	}
+	return report
+}
+
+func categoryKey(category string, depth int) string {
+	if category == "" {
+		return "Uncategorized"
+	}
+	return database.TruncateCategoryPath(category, depth)
+}
+
+func periodKey(date string, group string) string {
+	t, err := dateparse.ParseAny(date)
+	if err != nil {
+		return "unknown"
+	}
+	return FormatPeriod(t, group)
+}
+
+// FormatPeriod labels the week, month or year t falls in. Weeks starting on monday are ISO
+// weeks (2024-W05), other weeks are labeled with their first day. Fiscal years that do not
+// start in january are labeled with both years (2024/25).
+func FormatPeriod(t time.Time, group string) string {
+	switch group {
+	case "week":
+		if utils.WeekStart != time.Monday {
+			return "week of " + utils.StartOfWeek(t).Format("2006-01-02")
+		}
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "year":
+		start := utils.StartOfFiscalYear(t)
+		if start.Month() != time.January {
+			return fmt.Sprintf("%d/%02d", start.Year(), (start.Year()+1)%100)
+		}
+		return start.Format("2006")
+	default:
+		return t.Format("2006-01")
+	}
+}
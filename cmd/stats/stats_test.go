@@ -0,0 +1,96 @@
+package stats_test
+
+import (
+	"testing"
+
+	"github.com/elliot40404/acc/cmd/stats"
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestSummarize(t *testing.T) {
+	s := stats.Summarize([]database.Transaction{
+		{Type: "income", Amount: 100},
+		{Type: "expense", Amount: 30},
+		{Type: "expense", Amount: 20},
+		{Type: "income", Amount: 50},
+	})
+	if s.Count != 4 {
+		t.Error("expected 4, got", s.Count)
+	}
+	if s.Income != 150 || s.Expense != 50 || s.Net != 100 {
+		t.Error("expected 150/50/100, got", s.Income, s.Expense, s.Net)
+	}
+	if s.Average != 50 || s.Median != 40 || s.Max != 100 {
+		t.Error("expected 50/40/100, got", s.Average, s.Median, s.Max)
+	}
+	if s.AverageExpense != 25 || s.MedianExpense != 25 || s.MaxExpense != 30 {
+		t.Error("expected 25/25/30, got", s.AverageExpense, s.MedianExpense, s.MaxExpense)
+	}
+	// empty input
+	s = stats.Summarize(nil)
+	if s.Count != 0 || s.MedianExpense != 0 {
+		t.Error("expected empty summary, got", s)
+	}
+}
+
+func TestSummarizeMixedTypes(t *testing.T) {
+	s := stats.Summarize([]database.Transaction{
+		{Type: "income", Amount: 100000},
+		{Type: "expense", Amount: 350},
+		{Type: "expense", Amount: 1250},
+		{Type: "expense", Amount: 800},
+		{Type: "transfer", Amount: 50000},
+	})
+	if s.Count != 4 {
+		t.Error("expected the transfer not to be counted, got", s.Count)
+	}
+	if s.Income != 100000 || s.Expense != 2400 || s.Net != 97600 {
+		t.Error("expected 100000/2400/97600, got", s.Income, s.Expense, s.Net)
+	}
+	// the transfer is left out, the salary only counts for the per transaction metrics
+	if s.Average != 25600 || s.Median != 1025 || s.Max != 100000 {
+		t.Error("expected 25600/1025/100000, got", s.Average, s.Median, s.Max)
+	}
+	if s.AverageExpense != 800 || s.MedianExpense != 800 || s.MaxExpense != 1250 {
+		t.Error("expected 800/800/1250 for the expenses, got", s.AverageExpense, s.MedianExpense, s.MaxExpense)
+	}
+	r := stats.BuildReport([]database.Transaction{
+		{Type: "transfer", Amount: 50000, OccurredAt: "2024-01-15T10:00:00Z"},
+		{Type: "expense", Amount: 350, OccurredAt: "2024-02-15T10:00:00Z"},
+	}, "month", 0)
+	if len(r.Periods) != 1 || r.Periods[0].Period != "2024-02" {
+		t.Error("expected a month with only a transfer to be left out, got", r.Periods)
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	transactions := []database.Transaction{
+		{Type: "income", Amount: 100, OccurredAt: "2024-02-03T10:00:00Z"},
+		{Type: "expense", Amount: 30, OccurredAt: "2024-01-15T10:00:00Z"},
+		{Type: "expense", Amount: 20, OccurredAt: "2024-01-01T10:00:00Z"},
+	}
+	r := stats.BuildReport(transactions, "month", 0)
+	if len(r.Periods) != 2 {
+		t.Fatal("expected 2, got", len(r.Periods))
+	}
+	if r.Periods[0].Period != "2024-01" || r.Periods[0].Expense != 50 {
+		t.Error("expected 2024-01 with 50 expense, got", r.Periods[0])
+	}
+	r = stats.BuildReport(transactions, "week", 0)
+	if r.Periods[0].Period != "2024-W01" {
+		t.Error("expected 2024-W01, got", r.Periods[0].Period)
+	}
+	r = stats.BuildReport(transactions, "year", 0)
+	if len(r.Periods) != 1 || r.Total.Net != 50 {
+		t.Error("expected a single period with net 50, got", r)
+	}
+	transactions[0].Category = "Food > Groceries"
+	transactions[1].Category = "Food > Takeout"
+	r = stats.BuildReport(transactions, "category", 1)
+	if len(r.Periods) != 2 || r.Periods[0].Period != "Food" || r.Periods[0].Count != 2 {
+		t.Error("expected Food rolled up with 2 transactions, got", r.Periods)
+	}
+	if r.Periods[1].Period != "Uncategorized" {
+		t.Error("expected Uncategorized, got", r.Periods[1].Period)
+	}
+}
@@ -0,0 +1,29 @@
+package stats
+
+import (
+	"errors"
+)
+
+var validFormats = []string{
+	"table",
+	"json",
+	"csv",
+}
+
+func ValidateGroup(group string) error {
+	for _, validGroup := range validGroups {
+		if group == validGroup {
+			return nil
+		}
+	}
+	return errors.New("invalid group. group must be one of 'week', 'month', 'year' or 'category'")
+}
+
+func ValidateFormat(format string) error {
+	for _, validFormat := range validFormats {
+		if format == validFormat {
+			return nil
+		}
+	}
+	return errors.New("invalid format. format must be one of 'table', 'json' or 'csv'")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/elliot40404/acc/cmd/list"
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Show, restore and empty the transactions removed with acc rm",
+}
+
+var trashLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the transactions in the trash",
+	Args:  cobra.NoArgs,
+	Run:   TrashLs,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:     "restore",
+	Short:   "Take transactions out of the trash",
+	Example: `acc trash restore -i 4`,
+	Args:    cobra.NoArgs,
+	Run:     TrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Remove the transactions in the trash for good",
+	Example: `acc trash empty
+acc trash empty --older-than 30d`,
+	Args: cobra.NoArgs,
+	Run:  TrashEmpty,
+}
+
+func init() {
+	RootCmd.AddCommand(trashCmd)
+	trashCmd.AddCommand(trashLsCmd, trashRestoreCmd, trashEmptyCmd)
+	trashRestoreCmd.Flags().BoolP("all", "A", false, "Restore all transactions in the trash")
+	trashRestoreCmd.Flags().StringSliceP("id", "i", []string{}, "Restore a transaction by id. Example: -i 1 -i 2 or --id \"1,2\"")
+	trashRestoreCmd.MarkFlagsMutuallyExclusive("all", "id")
+	trashRestoreCmd.MarkFlagsOneRequired("all", "id")
+	trashEmptyCmd.Flags().String("older-than", "", "only remove transactions that have been in the trash this long (e.g. 30d, 2w, 12h)")
+}
+
+func TrashLs(cmd *cobra.Command, args []string) {
+	list.NonInteractiveListRenderer(database.TransactionConfig{
+		Dry:     cmd.Flag("dry").Value.String() == "true",
+		Verbose: cmd.Flag("verbose").Value.String() == "true",
+		Page:    1,
+		Limit:   1,
+		All:     true,
+		Deleted: true,
+		Columns: []string{"id", "date", "type", "amt", "cur", "desc", "cat", "deleted"},
+	})
+}
+
+func TrashRestore(cmd *cobra.Command, args []string) {
+	ids, _ := cmd.Flags().GetStringSlice("id")
+	if cmd.Flag("dry").Value.String() == "true" {
+		if len(ids) == 0 {
+			fmt.Println("Would restore all transactions in the trash")
+		} else {
+			fmt.Println("Would restore transactions", strings.Join(ids, ", "))
+		}
+		return
+	}
+	restored, err := database.NewTransactionRepository().RestoreTransactions(ids)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Restored %d transactions\n", restored)
+}
+
+func TrashEmpty(cmd *cobra.Command, args []string) {
+	var before string
+	if age := cmd.Flag("older-than").Value.String(); age != "" {
+		d, err := utils.ParseAge(age)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		before = time.Now().UTC().Add(-d).Format(utils.DBTimeFormat)
+	}
+	if cmd.Flag("dry").Value.String() == "true" {
+		if before == "" {
+			fmt.Println("Would remove all transactions in the trash")
+		} else {
+			fmt.Println("Would remove the transactions moved to the trash before", before)
+		}
+		return
+	}
+	if !utils.PromptConfirmation() {
+		fmt.Println("Aborted")
+		return
+	}
+	path, err := autoBackup("trash-empty")
+	if err != nil {
+		fmt.Println("failed to back up the database, nothing was removed:", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Backup saved to", path)
+	removed, err := database.NewTransactionRepository().EmptyTrash(before)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Removed %d transactions from the trash\n", removed)
+}
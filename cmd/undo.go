@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/utils"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Undo the last n changes (default: 1)",
+	Long: `Undo the last n changes (default: 1). Adding, editing, importing and removing transactions,
+categories, accounts, budgets, recurring transactions and exchange rates can be undone.`,
+	Example: `acc undo
+acc undo 3`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  Undo,
+}
+
+var redoCmd = &cobra.Command{
+	Use:     "redo [n]",
+	Short:   "Redo the last n undone changes (default: 1)",
+	Long:    `Redo the last n undone changes (default: 1). Undone changes can be redone until the next change is made.`,
+	Example: `acc redo`,
+	Args:    cobra.MaximumNArgs(1),
+	Run:     Redo,
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the latest changes that can be undone or redone",
+	Args:  cobra.NoArgs,
+	Run:   History,
+}
+
+func init() {
+	RootCmd.AddCommand(undoCmd, redoCmd, historyCmd)
+	historyCmd.Flags().IntP("limit", "l", 20, "number of changes to show")
+}
+
+func Undo(cmd *cobra.Command, args []string) {
+	replay(cmd, args, true)
+}
+
+func Redo(cmd *cobra.Command, args []string) {
+	replay(cmd, args, false)
+}
+
+func replay(cmd *cobra.Command, args []string, undo bool) {
+	n := 1
+	if len(args) == 1 {
+		var err error
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			fmt.Printf("invalid number '%s'\n", args[0])
+			return
+		}
+	}
+	verb := "Redid"
+	if undo {
+		verb = "Undid"
+	}
+	repo := database.NewJournalRepository()
+	var operations []database.Operation
+	var err error
+	switch {
+	case cmd.Flag("dry").Value.String() == "true":
+		verb = "Would redo"
+		if undo {
+			verb = "Would undo"
+		}
+		operations, err = pendingReplay(repo, n, undo)
+	case undo:
+		operations, err = repo.Undo(n)
+	default:
+		operations, err = repo.Redo(n)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if len(operations) == 0 {
+		if undo {
+			fmt.Println("Nothing to undo")
+		} else {
+			fmt.Println("Nothing to redo")
+		}
+		return
+	}
+	for _, operation := range operations {
+		fmt.Printf("%s %d: %s\n", verb, operation.ID, operation.Description)
+	}
+}
+
+// pendingReplay returns the operations Undo or Redo would replay, in the order they would be
+func pendingReplay(repo database.JournalRepository, n int, undo bool) ([]database.Operation, error) {
+	latest, err := repo.GetOperations(-1)
+	if err != nil {
+		return nil, err
+	}
+	var operations []database.Operation
+	if undo {
+		for _, operation := range latest {
+			if !operation.Undone && len(operations) < n {
+				operations = append(operations, operation)
+			}
+		}
+		return operations, nil
+	}
+	// undone operations are always the newest ones
+	for i := len(latest) - 1; i >= 0; i-- {
+		if latest[i].Undone && len(operations) < n {
+			operations = append(operations, latest[i])
+		}
+	}
+	return operations, nil
+}
+
+func History(cmd *cobra.Command, args []string) {
+	limit, _ := cmd.Flags().GetInt("limit")
+	operations, err := database.NewJournalRepository().GetOperations(limit)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	t := table.NewWriter()
+	t.AppendHeader(table.Row{"ID", "Time", "Change", "Rows", "Status"})
+	for _, operation := range operations {
+		status := "done"
+		if operation.Undone {
+			status = "undone"
+		}
+		t.AppendRow(table.Row{operation.ID, utils.HRTime(operation.CreatedAt), operation.Description, operation.Changes, status})
+	}
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.Render()
+}
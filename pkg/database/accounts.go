@@ -0,0 +1,157 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// id of the account transactions are booked on when no --account is given
+const DefaultAccountID = 1
+
+type accountRepository struct {
+	db *sqlx.DB
+}
+
+type Account struct {
+	ID        int    `db:"id" json:"id"`
+	Name      string `db:"name" json:"name"`
+	CreatedAt string `db:"created_at" json:"created_at"`
+}
+
+// AccountBalance is the running balance of an account in one currency, transfers move money
+// between accounts
+type AccountBalance struct {
+	Account
+	Currency    string      `db:"currency" json:"currency"`
+	Income      money.Money `db:"income" json:"income"`
+	Expense     money.Money `db:"expense" json:"expense"`
+	TransferIn  money.Money `db:"transfer_in" json:"transfer_in"`
+	TransferOut money.Money `db:"transfer_out" json:"transfer_out"`
+	Balance     money.Money `db:"balance" json:"balance"`
+}
+
+type AccountRepository interface {
+	CreateAccount(name string) error
+	FindAccount(name string) (int, error)
+	GetAccounts() ([]Account, error)
+	GetAccountBalances() ([]AccountBalance, error)
+	DeleteAccount(name string) error
+}
+
+func NewAccountRepository() AccountRepository {
+	db, err := GetDB()
+	if err != nil {
+		panic(err)
+	}
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) CreateAccount(name string) error {
+	if name == "" {
+		return errors.New("account name cannot be empty")
+	}
+	if _, err := r.FindAccount(name); err == nil {
+		return fmt.Errorf("account %q already exists", name)
+	}
+	return journal(r.db, fmt.Sprintf("add account %q", name), func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("INSERT INTO accounts (name) VALUES (?)", name)
+		return err
+	})
+}
+
+func (r *accountRepository) FindAccount(name string) (int, error) {
+	var id int
+	err := r.db.Get(&id, "SELECT id FROM accounts WHERE name = ?", name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("account %q not found", name)
+	}
+	return id, err
+}
+
+func (r *accountRepository) GetAccounts() ([]Account, error) {
+	var accounts []Account
+	err := r.db.Select(&accounts, "SELECT id, name, created_at FROM accounts ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *accountRepository) GetAccountBalances() ([]AccountBalance, error) {
+	base, err := baseCurrency(r.db)
+	if err != nil {
+		return nil, err
+	}
+	var balances []AccountBalance
+	err = r.db.Select(&balances, `
+		SELECT
+			a.id,
+			a.name,
+			a.created_at,
+			COALESCE(t.currency, ?) AS currency,
+			COALESCE(SUM(CASE WHEN t.type = 'income' AND t.account_id = a.id THEN t.amount END), 0) AS income,
+			COALESCE(SUM(CASE WHEN t.type = 'expense' AND t.account_id = a.id THEN t.amount END), 0) AS expense,
+			COALESCE(SUM(CASE WHEN t.type = 'transfer' AND t.to_account_id = a.id THEN t.amount END), 0) AS transfer_in,
+			COALESCE(SUM(CASE WHEN t.type = 'transfer' AND t.account_id = a.id THEN t.amount END), 0) AS transfer_out,
+			0 AS balance
+		FROM accounts a
+		LEFT JOIN transactions t ON (t.account_id = a.id OR t.to_account_id = a.id) AND t.deleted_at IS NULL
+		GROUP BY a.id, t.currency
+		ORDER BY a.id, t.currency`, base)
+	if err != nil {
+		return nil, err
+	}
+	for i := range balances {
+		b := &balances[i]
+		b.Balance = b.Income - b.Expense + b.TransferIn - b.TransferOut
+	}
+	return balances, nil
+}
+
+func (r *accountRepository) DeleteAccount(name string) error {
+	id, err := r.FindAccount(name)
+	if err != nil {
+		return err
+	}
+	if id == DefaultAccountID {
+		return errors.New("the default account cannot be removed")
+	}
+	// transactions in the trash count as well, they could be restored
+	var used int
+	err = r.db.Get(&used, "SELECT COUNT(*) FROM transactions WHERE account_id = ? OR to_account_id = ?", id, id)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return fmt.Errorf("account %q still has %d transactions", name, used)
+	}
+	err = r.db.Get(&used, "SELECT COUNT(*) FROM recurring_rules WHERE account_id = ? OR to_account_id = ?", id, id)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return fmt.Errorf("account %q is used by %d recurring transactions", name, used)
+	}
+	return journal(r.db, fmt.Sprintf("remove account %q", name), func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("DELETE FROM accounts WHERE id = ?", id)
+		return err
+	})
+}
+
+// accountNames maps every account id to its name
+func accountNames(db *sqlx.DB) (map[int]string, error) {
+	var accounts []Account
+	err := db.Select(&accounts, "SELECT id, name, created_at FROM accounts")
+	if err != nil {
+		return nil, err
+	}
+	names := map[int]string{}
+	for _, account := range accounts {
+		names[account.ID] = account.Name
+	}
+	return names, nil
+}
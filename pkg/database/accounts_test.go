@@ -0,0 +1,47 @@
+package database_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+func TestAccountBalances(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	accounts := database.NewAccountRepository()
+	if err := accounts.CreateAccount("savings"); err != nil {
+		t.Fatal(err)
+	}
+	savings, err := accounts.FindAccount("savings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = database.NewTransactionRepository().CreateTransactions([]database.Transaction{
+		{Type: "income", Description: "salary", Amount: money.Money(100000)},
+		{Type: "transfer", Description: "save", Amount: money.Money(30000), ToAccountID: &savings},
+		{Type: "expense", Description: "gift", Amount: money.Money(5000), AccountID: savings},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balances, err := accounts.GetAccountBalances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 {
+		t.Fatal("expected a balance per account, got", balances)
+	}
+	checking, saved := balances[0], balances[1]
+	// the transfer is neither income nor expense, it moves money between the accounts
+	if checking.Income != 100000 || checking.Expense != 0 || checking.TransferOut != 30000 || checking.Balance != 70000 {
+		t.Error("expected 1000.00 in, 300.00 transferred out and 700.00 left on default, got", checking)
+	}
+	if saved.Name != "savings" || saved.Income != 0 || saved.Expense != 5000 || saved.TransferIn != 30000 || saved.Balance != 25000 {
+		t.Error("expected 300.00 transferred in, 50.00 spent and 250.00 left on savings, got", saved)
+	}
+}
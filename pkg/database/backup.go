@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// pages copied per backup step, other connections can write to the database in between
+	backupStepPages = 256
+	backupStepPause = 5 * time.Millisecond
+	// how long a backup waits for a writer that keeps the database locked
+	backupBusyTimeout = 30 * time.Second
+)
+
+// Backup writes a consistent copy of the database to path, which must not exist yet. The
+// copy is made with the online backup API, so other acc processes may keep writing.
+func Backup(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+	return copyDatabase(db, path)
+}
+
+// BackupFile is Backup for the database at src instead of the one in use
+func BackupFile(src string, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	db, err := sqlx.Open("sqlite3", "file:"+src+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return copyDatabase(db, path)
+}
+
+// copyDatabase copies the main database of src to path, nothing is left at path on failure
+func copyDatabase(src *sqlx.DB, path string) (err error) {
+	dest, err := sqlx.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		dest.Close()
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+	return destConn.Raw(func(destDriver interface{}) error {
+		return srcConn.Raw(func(srcDriver interface{}) error {
+			backup, err := destDriver.(*sqlite3.SQLiteConn).Backup("main", srcDriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			defer backup.Finish()
+			remaining, deadline := -1, time.Now().Add(backupBusyTimeout)
+			for {
+				done, err := backup.Step(backupStepPages)
+				if err != nil {
+					return err
+				}
+				if done {
+					return backup.Finish()
+				}
+				// Step does not report busy or locked databases, they show as no progress
+				if r := backup.Remaining(); r != remaining {
+					remaining, deadline = r, time.Now().Add(backupBusyTimeout)
+				} else if time.Now().After(deadline) {
+					return errors.New("database is locked by another process")
+				}
+				time.Sleep(backupStepPause)
+			}
+		})
+	})
+}
+
+// VerifyDatabase checks that path is an intact acc database this version of acc can open
+// and returns its schema version
+func VerifyDatabase(path string) (int, error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	db, err := sqlx.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	var result string
+	if err := db.Get(&result, "PRAGMA integrity_check(1)"); err != nil {
+		return 0, fmt.Errorf("%s is not a readable database: %w", path, err)
+	}
+	if result != "ok" {
+		return 0, fmt.Errorf("%s is damaged: %s", path, result)
+	}
+	var tables int
+	err = db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'transactions'")
+	if err != nil {
+		return 0, err
+	}
+	if tables == 0 {
+		return 0, fmt.Errorf("%s is not an acc database", path)
+	}
+	var version int
+	if err := db.Get(&version, "PRAGMA user_version"); err != nil {
+		return 0, err
+	}
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	if version > len(migrations) {
+		return 0, fmt.Errorf("%s has schema version %d, newer than this version of acc supports (%d)", path, version, len(migrations))
+	}
+	return version, nil
+}
+
+// Restore replaces the database with the backup at path. The backup is verified and copied
+// next to the database first, the database file is only swapped once the copy is verified.
+func Restore(path string) error {
+	if _, err := VerifyDatabase(path); err != nil {
+		return err
+	}
+	src, err := sqlx.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	staged := DBPATH + ".restore"
+	// left over by an interrupted restore
+	if err := os.Remove(staged); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := copyDatabase(src, staged); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", path, err)
+	}
+	if _, err := VerifyDatabase(staged); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	if conn != nil {
+		conn.Close()
+		conn = nil
+	}
+	if err := os.Rename(staged, DBPATH); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package database_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	database.DBPATH = filepath.Join(dir, "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	transactions := database.NewTransactionRepository()
+	_, err := transactions.CreateTransaction(database.Transaction{Type: "expense", Description: "coffee", Amount: money.Money(350)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "backup.db")
+	if err := database.Backup(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Backup(path); err == nil {
+		t.Error("expected an existing backup not to be overwritten")
+	}
+	migrations, _ := database.Migrations()
+	if version, err := database.VerifyDatabase(path); err != nil || version != len(migrations) {
+		t.Error("expected a backup at version", len(migrations), "got", version, err)
+	}
+	if _, err := transactions.CreateTransaction(database.Transaction{Type: "expense", Description: "tea", Amount: money.Money(250)}); err != nil {
+		t.Fatal(err)
+	}
+	damaged := filepath.Join(dir, "damaged.db")
+	if err := os.WriteFile(damaged, []byte("not a database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Restore(damaged); err == nil {
+		t.Error("expected a damaged backup not to be restored")
+	}
+	if n := countTransactions(t); n != 2 {
+		t.Fatal("expected the database to be unchanged, got", n, "transactions")
+	}
+	if err := database.Restore(path); err != nil {
+		t.Fatal(err)
+	}
+	if n := countTransactions(t); n != 1 {
+		t.Error("expected the transaction of the backup, got", n)
+	}
+	if _, err := os.Stat(database.DBPATH + ".restore"); err == nil {
+		t.Error("expected the staged copy to be gone")
+	}
+}
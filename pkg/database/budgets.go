@@ -0,0 +1,105 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/jmoiron/sqlx"
+)
+
+type budgetRepository struct {
+	db *sqlx.DB
+}
+
+// Budget limits the expenses of a category and its subcategories per week, month or year
+// in the base currency. With Rollover the unspent amount of every period since StartDate is
+// added to the next one.
+type Budget struct {
+	ID         int         `db:"id" json:"id"`
+	CategoryID int         `db:"category_id" json:"category_id"`
+	Category   string      `db:"-" json:"category"`
+	Amount     money.Money `db:"amount" json:"amount"`
+	Period     string      `db:"period" json:"period"`
+	Rollover   bool        `db:"rollover" json:"rollover"`
+	StartDate  string      `db:"start_date" json:"start_date"`
+	CreatedAt  string      `db:"created_at" json:"created_at"`
+}
+
+type BudgetRepository interface {
+	// SetBudget creates or replaces the budget of the category, the start date of an existing
+	// budget is kept unless its period changes
+	SetBudget(budget Budget) error
+	GetBudgets() ([]Budget, error)
+	DeleteBudget(category string) error
+}
+
+func NewBudgetRepository() BudgetRepository {
+	db, err := GetDB()
+	if err != nil {
+		panic(err)
+	}
+	return &budgetRepository{db: db}
+}
+
+func (r *budgetRepository) SetBudget(budget Budget) error {
+	if budget.Amount <= 0 {
+		return errors.New("invalid amount. a budget must be greater than 0")
+	}
+	return journal(r.db, fmt.Sprintf("set budget of %q", budget.Category), func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO budgets (category_id, amount, period, rollover, start_date) VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (category_id) DO UPDATE SET
+				amount = excluded.amount,
+				rollover = excluded.rollover,
+				start_date = CASE WHEN period = excluded.period THEN start_date ELSE excluded.start_date END,
+				period = excluded.period`,
+			budget.CategoryID, budget.Amount, budget.Period, budget.Rollover, budget.StartDate,
+		)
+		return err
+	})
+}
+
+// GetBudgets returns the budgets ordered by category path
+func (r *budgetRepository) GetBudgets() ([]Budget, error) {
+	var budgets []Budget
+	err := r.db.Select(&budgets, "SELECT * FROM budgets")
+	if err != nil {
+		return nil, err
+	}
+	var categories []Category
+	err = r.db.Select(&categories, "SELECT id, name, parent_id FROM categories")
+	if err != nil {
+		return nil, err
+	}
+	paths := CategoryPaths(categories)
+	for i := range budgets {
+		budgets[i].Category = paths[budgets[i].CategoryID]
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		return budgets[i].Category < budgets[j].Category
+	})
+	return budgets, nil
+}
+
+func (r *budgetRepository) DeleteBudget(category string) error {
+	id, err := (&categoryRepository{db: r.db}).FindCategory(category)
+	if err != nil {
+		return err
+	}
+	return journal(r.db, fmt.Sprintf("remove budget of %q", category), func(tx *sqlx.Tx) error {
+		res, err := tx.Exec("DELETE FROM budgets WHERE category_id = ?", id)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("category %q has no budget", category)
+		}
+		return nil
+	})
+}
@@ -1,8 +1,10 @@
 package database
 
 import (
-	"fmt"
+	"strings"
+	"time"
 
+	"github.com/elliot40404/acc/pkg/money"
 	"github.com/elliot40404/acc/pkg/utils"
 )
 
@@ -11,11 +13,35 @@ var colMap = map[string]string{
 	"type": "type",
 	"amt":  "amount",
 	"desc": "description",
-	"date": "created_at",
+	"date": "occurred_at",
+	"cat":  "category_id",
 }
 
-func (q *TQuery) Build() string {
-	return q.Query
+// predicate is one condition of the WHERE clause, user input only ever ends up in args
+type predicate struct {
+	sql  string
+	args []interface{}
+}
+
+// Build joins the predicates with AND and returns the query with its bound args
+func (q *TQuery) Build() (string, []interface{}) {
+	query := q.Query
+	var args []interface{}
+	var conditions []string
+	for _, p := range q.predicates {
+		conditions = append(conditions, p.sql)
+		args = append(args, p.args...)
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += q.suffix
+	args = append(args, q.suffixArgs...)
+	return query, args
+}
+
+func (q *TQuery) where(sql string, args ...interface{}) {
+	q.predicates = append(q.predicates, predicate{sql: sql, args: args})
 }
 
 func (q *TQuery) AddColumns() {
@@ -40,132 +66,200 @@ func (q *TQuery) AddColumns() {
 
 func (q *TQuery) AddType() {
 	if q.Config.TxType != "" {
-		q.Query += fmt.Sprintf(" WHERE type = '%s'", q.Config.TxType)
+		q.where("type = ?", q.Config.TxType)
 	}
 }
 
 func (q *TQuery) AddDate() {
 	if q.Config.Date != "" {
-		var dateQuery string
+		build := buildDateQuery
 		if utils.IsValueRange(q.Config.Date) {
-			dateQuery += buildDateRangeQuery(q.Config.Date)
-		} else {
-			dateQuery += buildDateQuery(q.Config.Date)
-		}
-		if q.Config.TxType != "" && dateQuery != "" {
-			dateQuery = " AND" + dateQuery
-		} else if dateQuery != "" {
-			dateQuery = " WHERE" + dateQuery
+			build = buildDateRangeQuery
 		}
-		q.Query += dateQuery
+		sql, args := build(q.Config.Date)
+		q.where(sql, args...)
 	}
 }
 
 func (q *TQuery) AddAmount() {
 	if q.Config.Amount != "" {
-		var amountQuery string
 		if utils.IsValueRange(q.Config.Amount) {
-			amountQuery += buildAmountRangeQuery(q.Config.Amount)
+			sql, args := buildAmountRangeQuery(q.Config.Amount)
+			q.where(sql, args...)
 		} else {
-			amountQuery += buildAmountQuery(q.Config.Amount)
-		}
-		if (q.Config.TxType != "" || q.Config.Date != "") && amountQuery != "" {
-			amountQuery = " AND" + amountQuery
-		} else if amountQuery != "" {
-			amountQuery = " WHERE" + amountQuery
+			q.where("amount = ?", minorUnits(q.Config.Amount))
 		}
-		q.Query += amountQuery
 	}
 }
 
+// likeEscaper makes % and _ in user input match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (q *TQuery) AddDesc() {
 	if q.Config.Desc != "" {
-		var descQuery string
-		if q.Config.TxType != "" || q.Config.Date != "" || q.Config.Amount != "" {
-			descQuery += " AND"
-		} else {
-			descQuery += " WHERE"
-		}
-		descQuery += fmt.Sprintf(" description LIKE '%%%s%%'", q.Config.Desc)
-		q.Query += descQuery
+		q.where(`description LIKE ? ESCAPE '\'`, "%"+likeEscaper.Replace(q.Config.Desc)+"%")
+	}
+}
+
+// AddSearch matches descriptions and notes against the full-text index
+func (q *TQuery) AddSearch() error {
+	if q.Config.Search == "" {
+		return nil
+	}
+	match, err := FTSQuery(q.Config.Search)
+	if err != nil {
+		return err
+	}
+	q.match = match
+	q.where("id IN (SELECT rowid FROM transactions_fts WHERE transactions_fts MATCH ?)", match)
+	return nil
+}
+
+// AddCategory matches the category and all of its descendants
+func (q *TQuery) AddCategory() {
+	if q.Config.CategoryID != 0 {
+		q.where(
+			"category_id IN (WITH RECURSIVE tree(id) AS (SELECT ? UNION ALL SELECT c.id FROM categories c JOIN tree ON c.parent_id = tree.id) SELECT id FROM tree)",
+			q.Config.CategoryID,
+		)
+	}
+}
+
+// AddTags matches any (or all, with TagMode "all") of the tags and none of the negated ones
+func (q *TQuery) AddTags() {
+	include, exclude := SplitTagFilter(q.Config.Tags)
+	q.predicates = append(q.predicates, buildTagQuery(include, exclude, q.Config.TagMode == "all")...)
+}
+
+// AddAccount matches transactions booked on the account and transfers into it
+func (q *TQuery) AddAccount() {
+	if q.Config.AccountID != 0 {
+		q.where("(account_id = ? OR to_account_id = ?)", q.Config.AccountID, q.Config.AccountID)
+	}
+}
+
+func (q *TQuery) AddCurrency() {
+	if q.Config.Currency != "" {
+		q.where("currency = ?", q.Config.Currency)
+	}
+}
+
+// AddDeleted leaves out the transactions in the trash unless they are asked for
+func (q *TQuery) AddDeleted() {
+	switch {
+	case q.Config.Deleted:
+		q.where("deleted_at IS NOT NULL")
+	case !q.Config.IncludeDeleted:
+		q.where("deleted_at IS NULL")
 	}
 }
 
 func (q *TQuery) AddLimit() {
 	if q.Config.Limit != 0 {
-		q.Query += fmt.Sprintf(" LIMIT %d", q.Config.Limit)
+		q.suffix += " LIMIT ?"
+		q.suffixArgs = append(q.suffixArgs, q.Config.Limit)
 	}
 }
 
 func (q *TQuery) AddOffset() {
 	if q.Config.Page != 0 {
-		q.Query += fmt.Sprintf(" OFFSET %d", (q.Config.Page-1)*q.Config.Limit)
+		q.suffix += " OFFSET ?"
+		q.suffixArgs = append(q.suffixArgs, (q.Config.Page-1)*q.Config.Limit)
 	}
 }
 
+// AddSort orders by a whitelisted column, column names cannot be bound as args
 func (q *TQuery) AddSort() {
-	if q.Config.Sort != "" {
-		if q.Config.Sort == "date" {
-			q.Config.Sort = "created_at"
-		} else if q.Config.Sort == "amt" {
-			q.Config.Sort = "amount"
-		}
-		q.Query += fmt.Sprintf(" ORDER BY %s", q.Config.Sort)
-		if q.Config.SortAsc {
-			q.Query += " ASC"
-		} else {
-			q.Query += " DESC"
-		}
+	if q.Config.Sort == "relevance" {
+		q.addRelevanceSort()
+		return
+	}
+	column, ok := map[string]string{"date": "occurred_at", "amt": "amount"}[q.Config.Sort]
+	if !ok {
+		return
+	}
+	q.suffix += " ORDER BY " + column
+	if q.Config.SortAsc {
+		q.suffix += " ASC"
+	} else {
+		q.suffix += " DESC"
 	}
 }
 
-func buildDateQuery(date string) string {
+// addRelevanceSort orders by bm25 of the search, which is lower for better matches so the
+// best ones come first unless SortAsc is set. Descriptions weigh twice as much as notes.
+func (q *TQuery) addRelevanceSort() {
+	if q.match == "" {
+		return
+	}
+	q.suffix += " ORDER BY (SELECT bm25(transactions_fts, 2.0, 1.0) FROM transactions_fts WHERE transactions_fts MATCH ? AND rowid = transactions.id)"
+	q.suffixArgs = append(q.suffixArgs, q.match)
+	if q.Config.SortAsc {
+		q.suffix += " DESC"
+	} else {
+		q.suffix += " ASC"
+	}
+}
+
+func buildDateQuery(date string) (string, []interface{}) {
 	switch date {
 	case "today":
-		return " DATE(created_at) = DATE('now')"
+		return "DATE(occurred_at) = DATE('now')", nil
 	case "yesterday":
-		return " DATE(created_at) = DATE('now', '-1 day')"
-	case "thisweek":
-		return " strftime('%W', created_at) = strftime('%W', 'now')"
-	case "lastweek":
-		return " strftime('%W', created_at) = strftime('%W', 'now', '-7 days')"
-	case "thismonth":
-		return " strftime('%m', created_at) = strftime('%m', 'now')"
-	case "lastmonth":
-		return " strftime('%m', created_at) = strftime('%m', 'now', '-1 month')"
-	case "thisyear":
-		return " strftime('%Y', created_at) = strftime('%Y', 'now')"
-	case "lastyear":
-		return " strftime('%Y', created_at) = strftime('%Y', 'now', '-1 year')"
+		return "DATE(occurred_at) = DATE('now', '-1 day')", nil
+	case "thisweek", "lastweek":
+		// weeks start on utils.WeekStart, which strftime knows nothing about
+		start := utils.StartOfWeek(time.Now().UTC())
+		if date == "lastweek" {
+			start = start.AddDate(0, 0, -7)
+		}
+		return "DATE(occurred_at) BETWEEN DATE(?) AND DATE(?)", []interface{}{start.Format(ScheduleDateFormat), start.AddDate(0, 0, 6).Format(ScheduleDateFormat)}
+	case "thismonth", "lastmonth":
+		// the month of the same year, not that month of every year
+		now := time.Now().UTC()
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if date == "lastmonth" {
+			start = start.AddDate(0, -1, 0)
+		}
+		return "DATE(occurred_at) BETWEEN DATE(?) AND DATE(?)", []interface{}{start.Format(ScheduleDateFormat), start.AddDate(0, 1, -1).Format(ScheduleDateFormat)}
+	case "thisyear", "lastyear":
+		// fiscal years start in utils.FiscalYearStart
+		start := utils.StartOfFiscalYear(time.Now().UTC())
+		if date == "lastyear" {
+			start = start.AddDate(-1, 0, 0)
+		}
+		return "DATE(occurred_at) BETWEEN DATE(?) AND DATE(?)", []interface{}{start.Format(ScheduleDateFormat), start.AddDate(1, 0, -1).Format(ScheduleDateFormat)}
 	default:
-		return " DATE(created_at) = DATE('" + utils.ConvertToDateFormat(date) + "')"
+		return "DATE(occurred_at) = DATE(?)", []interface{}{utils.ConvertToDateFormat(date)}
 	}
 }
 
-func buildDateRangeQuery(date string) string {
+func buildDateRangeQuery(date string) (string, []interface{}) {
 	if date[0] == ':' {
-		return " DATE(created_at) <= DATE('" + utils.ConvertToDateFormat(date[1:]) + "')"
+		return "DATE(occurred_at) <= DATE(?)", []interface{}{utils.ConvertToDateFormat(date[1:])}
 	} else if date[len(date)-1] == ':' {
-		return " DATE(created_at) >= DATE('" + utils.ConvertToDateFormat(date[:len(date)-1]) + "')"
+		return "DATE(occurred_at) >= DATE(?)", []interface{}{utils.ConvertToDateFormat(date[:len(date)-1])}
 	} else {
 		dates := utils.SplitDateRange(date)
 		// NOTE: can throw a warning if dates[0] > dates[1]
-		return " DATE(created_at) BETWEEN DATE('" + dates[0] + "') AND DATE('" + dates[1] + "')"
+		return "DATE(occurred_at) BETWEEN DATE(?) AND DATE(?)", []interface{}{dates[0], dates[1]}
 	}
 }
 
-func buildAmountQuery(amount string) string {
-	return " amount = " + amount
+// minorUnits converts a validated amount like 12.34 to the integer stored in the database
+func minorUnits(amount string) int64 {
+	m, _ := money.Parse(amount)
+	return int64(m)
 }
 
-func buildAmountRangeQuery(amount string) string {
+func buildAmountRangeQuery(amount string) (string, []interface{}) {
 	if amount[0] == ':' {
-		return " amount <= " + amount[1:]
+		return "amount <= ?", []interface{}{minorUnits(amount[1:])}
 	} else if amount[len(amount)-1] == ':' {
-		return " amount >= " + amount[:len(amount)-1]
+		return "amount >= ?", []interface{}{minorUnits(amount[:len(amount)-1])}
 	} else {
 		amounts := utils.SplitAmountRange(amount)
 		// NOTE: can throw a warning if amounts[0] > amounts[1]
-		return " amount BETWEEN " + amounts[0] + " AND " + amounts[1]
+		return "amount BETWEEN ? AND ?", []interface{}{minorUnits(amounts[0]), minorUnits(amounts[1])}
 	}
 }
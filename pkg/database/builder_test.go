@@ -0,0 +1,98 @@
+package database_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestBuildBindsUserInput(t *testing.T) {
+	q := database.NewQuery(database.TransactionConfig{
+		TxType: "expense",
+		Desc:   "x' OR 1=1 --",
+		Amount: "10:20.5",
+		Date:   "2024-01-01:2024-01-31",
+	}, false)
+	q.AddType()
+	q.AddDate()
+	q.AddAmount()
+	q.AddDesc()
+	query, args := q.Build()
+	want := `SELECT * FROM transactions WHERE type = ? AND DATE(occurred_at) BETWEEN DATE(?) AND DATE(?) AND amount BETWEEN ? AND ? AND description LIKE ? ESCAPE '\'`
+	if query != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, query)
+	}
+	wantArgs := []interface{}{"expense", "2024-01-01", "2024-01-31", int64(1000), int64(2050), "%x' OR 1=1 --%"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("expected %v, got %v", wantArgs, args)
+	}
+}
+
+func TestBuildWithoutPredicates(t *testing.T) {
+	q := database.NewQuery(database.TransactionConfig{Page: 3, Limit: 10, Sort: "amt", SortAsc: true}, false)
+	q.AddType()
+	q.AddTags()
+	q.AddSort()
+	q.AddLimit()
+	q.AddOffset()
+	query, args := q.Build()
+	if query != "SELECT * FROM transactions ORDER BY amount ASC LIMIT ? OFFSET ?" {
+		t.Error("unexpected query", query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{10, 20}) {
+		t.Error("expected [10 20], got", args)
+	}
+}
+
+func TestBuildTags(t *testing.T) {
+	q := database.NewQuery(database.TransactionConfig{Tags: []string{"trip", "#food", "!work"}, TagMode: "all"}, true)
+	q.AddTags()
+	query, args := q.Build()
+	want := "SELECT COUNT(*) FROM transactions WHERE " +
+		"id IN (SELECT tt.transaction_id FROM transaction_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.name IN (?, ?) GROUP BY tt.transaction_id HAVING COUNT(DISTINCT t.name) = ?) AND " +
+		"id NOT IN (SELECT tt.transaction_id FROM transaction_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.name IN (?))"
+	if query != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, query)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"trip", "food", 2, "work"}) {
+		t.Error("unexpected args", args)
+	}
+}
+
+func TestBuildSearchByRelevance(t *testing.T) {
+	q := database.NewQuery(database.TransactionConfig{Search: "tea -green", Sort: "relevance"}, false)
+	if err := q.AddSearch(); err != nil {
+		t.Fatal(err)
+	}
+	q.AddSort()
+	query, args := q.Build()
+	want := "SELECT * FROM transactions WHERE id IN (SELECT rowid FROM transactions_fts WHERE transactions_fts MATCH ?)" +
+		" ORDER BY (SELECT bm25(transactions_fts, 2.0, 1.0) FROM transactions_fts WHERE transactions_fts MATCH ? AND rowid = transactions.id) ASC"
+	if query != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, query)
+	}
+	match := `("tea"*) NOT "green"*`
+	if !reflect.DeepEqual(args, []interface{}{match, match}) {
+		t.Errorf("expected the match expression twice, got %v", args)
+	}
+}
+
+func TestBuildMonthRanges(t *testing.T) {
+	now := time.Now().UTC()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for date, start := range map[string]time.Time{"thismonth": first, "lastmonth": first.AddDate(0, -1, 0)} {
+		q := database.NewQuery(database.TransactionConfig{Date: date}, false)
+		q.AddDate()
+		query, args := q.Build()
+		if query != "SELECT * FROM transactions WHERE DATE(occurred_at) BETWEEN DATE(?) AND DATE(?)" {
+			t.Error("expected a range of dates for", date, "got", query)
+		}
+		// the range includes the year, lastmonth in january is december of last year
+		want := []interface{}{start.Format("2006-01-02"), start.AddDate(0, 1, -1).Format("2006-01-02")}
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("expected %v for %s, got %v", want, date, args)
+		}
+	}
+}
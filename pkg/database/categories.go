@@ -0,0 +1,236 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// separator used when writing category paths like "Food > Groceries"
+const CategorySeparator = " > "
+
+type categoryRepository struct {
+	db *sqlx.DB
+}
+
+type Category struct {
+	ID       int    `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	ParentID *int   `db:"parent_id" json:"parent_id"`
+}
+
+type CategoryRepository interface {
+	CreateCategory(path string) (int, error)
+	FindCategory(path string) (int, error)
+	GetCategories() ([]Category, error)
+	DeleteCategory(path string) error
+	MoveCategory(from string, to string) error
+}
+
+func NewCategoryRepository() CategoryRepository {
+	db, err := GetDB()
+	if err != nil {
+		panic(err)
+	}
+	return &categoryRepository{db: db}
+}
+
+// SplitCategoryPath turns "Food > Groceries" (or "Food>Groceries") into its segments
+func SplitCategoryPath(path string) ([]string, error) {
+	var segments []string
+	for _, segment := range strings.Split(path, ">") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			return nil, fmt.Errorf("invalid category %q", path)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// CategoryPaths maps every category id to its full path
+func CategoryPaths(categories []Category) map[int]string {
+	byID := map[int]Category{}
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	paths := map[int]string{}
+	var resolve func(id int, depth int) string
+	resolve = func(id int, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		category := byID[id]
+		path := category.Name
+		// depth guards against cycles in a corrupted tree
+		if category.ParentID != nil && depth < len(byID) {
+			path = resolve(*category.ParentID, depth+1) + CategorySeparator + path
+		}
+		paths[id] = path
+		return path
+	}
+	for _, category := range categories {
+		resolve(category.ID, 0)
+	}
+	return paths
+}
+
+// TruncateCategoryPath keeps the first depth levels of a path, depth < 1 keeps everything
+func TruncateCategoryPath(path string, depth int) string {
+	segments := strings.Split(path, CategorySeparator)
+	if depth < 1 || depth >= len(segments) {
+		return path
+	}
+	return strings.Join(segments[:depth], CategorySeparator)
+}
+
+func (r *categoryRepository) findChild(parentID *int, name string) (int, error) {
+	return findChild(r.db, parentID, name)
+}
+
+func findChild(q sqlx.Queryer, parentID *int, name string) (int, error) {
+	var id int
+	err := sqlx.Get(q, &id, "SELECT id FROM categories WHERE COALESCE(parent_id, 0) = COALESCE(?, 0) AND name = ?", parentID, name)
+	return id, err
+}
+
+func (r *categoryRepository) CreateCategory(path string) (int, error) {
+	var id int
+	err := journal(r.db, fmt.Sprintf("add category %q", path), func(tx *sqlx.Tx) error {
+		var err error
+		id, err = createCategory(tx, path)
+		return err
+	})
+	return id, err
+}
+
+// createCategory creates every missing segment of path and returns the id of the last one
+func createCategory(q sqlx.Ext, path string) (int, error) {
+	segments, err := SplitCategoryPath(path)
+	if err != nil {
+		return 0, err
+	}
+	var parentID *int
+	var id int
+	for _, segment := range segments {
+		id, err = findChild(q, parentID, segment)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+		if err != nil {
+			res, err := q.Exec("INSERT INTO categories (name, parent_id) VALUES (?, ?)", segment, parentID)
+			if err != nil {
+				return 0, err
+			}
+			lastID, err := res.LastInsertId()
+			if err != nil {
+				return 0, err
+			}
+			id = int(lastID)
+		}
+		current := id
+		parentID = &current
+	}
+	return id, nil
+}
+
+func (r *categoryRepository) FindCategory(path string) (int, error) {
+	segments, err := SplitCategoryPath(path)
+	if err != nil {
+		return 0, err
+	}
+	var parentID *int
+	var id int
+	for _, segment := range segments {
+		id, err = r.findChild(parentID, segment)
+		if err != nil {
+			return 0, fmt.Errorf("category %q not found", path)
+		}
+		current := id
+		parentID = &current
+	}
+	return id, nil
+}
+
+func (r *categoryRepository) GetCategories() ([]Category, error) {
+	var categories []Category
+	err := r.db.Select(&categories, "SELECT id, name, parent_id FROM categories ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) DeleteCategory(path string) error {
+	id, err := r.FindCategory(path)
+	if err != nil {
+		return err
+	}
+	var children int
+	err = r.db.Get(&children, "SELECT COUNT(*) FROM categories WHERE parent_id = ?", id)
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return fmt.Errorf("category %q has subcategories, remove or move them first", path)
+	}
+	return journal(r.db, fmt.Sprintf("remove category %q", path), func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("UPDATE transactions SET category_id = NULL WHERE category_id = ?", id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE recurring_rules SET category_id = NULL WHERE category_id = ?", id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM budgets WHERE category_id = ?", id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM categories WHERE id = ?", id)
+		return err
+	})
+}
+
+// MoveCategory renames and/or reparents a category, the parent of the destination must exist
+func (r *categoryRepository) MoveCategory(from string, to string) error {
+	id, err := r.FindCategory(from)
+	if err != nil {
+		return err
+	}
+	segments, err := SplitCategoryPath(to)
+	if err != nil {
+		return err
+	}
+	var parentID *int
+	if len(segments) > 1 {
+		pid, err := r.FindCategory(strings.Join(segments[:len(segments)-1], CategorySeparator))
+		if err != nil {
+			return err
+		}
+		parentID = &pid
+	}
+	name := segments[len(segments)-1]
+	if _, err := r.findChild(parentID, name); err == nil {
+		return fmt.Errorf("category %q already exists", to)
+	}
+	// walk up from the new parent to make sure we are not moving a category below itself
+	for p := parentID; p != nil; {
+		if *p == id {
+			return errors.New("cannot move a category into its own subcategory")
+		}
+		var next *int
+		err = r.db.Get(&next, "SELECT parent_id FROM categories WHERE id = ?", *p)
+		if err != nil {
+			return err
+		}
+		p = next
+	}
+	return journal(r.db, fmt.Sprintf("move category %q to %q", from, to), func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("UPDATE categories SET name = ?, parent_id = ? WHERE id = ?", name, parentID, id)
+		return err
+	})
+}
@@ -0,0 +1,40 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestSplitCategoryPath(t *testing.T) {
+	r, err := database.SplitCategoryPath("Food > Groceries")
+	if err != nil || len(r) != 2 || r[1] != "Groceries" {
+		t.Error("expected [Food Groceries], got", r, err)
+	}
+	r, _ = database.SplitCategoryPath("Food>Groceries")
+	if len(r) != 2 {
+		t.Error("expected 2, got", len(r))
+	}
+	_, err = database.SplitCategoryPath("Food > ")
+	if err == nil {
+		t.Error("expected error for empty segment")
+	}
+}
+
+func TestCategoryPaths(t *testing.T) {
+	food, groceries := 1, 2
+	paths := database.CategoryPaths([]database.Category{
+		{ID: 3, Name: "Fruit", ParentID: &groceries},
+		{ID: food, Name: "Food"},
+		{ID: groceries, Name: "Groceries", ParentID: &food},
+	})
+	if paths[3] != "Food > Groceries > Fruit" {
+		t.Error("expected Food > Groceries > Fruit, got", paths[3])
+	}
+	if r := database.TruncateCategoryPath(paths[3], 1); r != "Food" {
+		t.Error("expected Food, got", r)
+	}
+	if r := database.TruncateCategoryPath(paths[3], 0); r != paths[3] {
+		t.Error("expected full path, got", r)
+	}
+}
@@ -15,18 +15,31 @@ var schema string
 
 var DBPATH = utils.DBPATH()
 
+// shared handle so long running sessions (acc shell) reuse a single connection pool
+var conn *sqlx.DB
+
+// path conn was opened with, a new pool is opened when DBPATH changes
+var connPath string
+
 func GetDB() (*sqlx.DB, error) {
+	if conn != nil && connPath == DBPATH {
+		return conn, nil
+	}
 	db, err := sqlx.Open("sqlite3", DBPATH)
 	if err != nil {
 		slog.Error("DB: failed to open database", "Error", err.Error())
 		return nil, errors.New("failed to open database")
 	}
-	return db, nil
+	if conn != nil {
+		conn.Close()
+	}
+	conn, connPath = db, DBPATH
+	return conn, nil
 }
 
+// InitApplication creates the version 0 schema and migrates it to the latest version
 func InitApplication() error {
-	path := utils.DBPATH()
-	db, err := sqlx.Open("sqlite3", path)
+	db, err := sqlx.Open("sqlite3", DBPATH)
 	if err != nil {
 		slog.Error("DB: failed to open database", "Error", err.Error())
 		return errors.New("failed to open database")
@@ -36,5 +49,9 @@ func InitApplication() error {
 		slog.Error("DB: failed to initialize database schema", "Error", err.Error())
 		return errors.New("failed to initialize database schema")
 	}
-	return nil
-}
\ No newline at end of file
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	return migrate(db, migrations, len(migrations))
+}
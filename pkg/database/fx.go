@@ -0,0 +1,163 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// currency used until the user picks another one with `acc fx base`
+const DefaultBaseCurrency = "USD"
+
+type fxRepository struct {
+	db *sqlx.DB
+}
+
+// FxRate is the price of one unit of From in To, effective from Date (YYYY-MM-DD) onwards
+type FxRate struct {
+	From string `db:"from_currency" json:"from"`
+	To   string `db:"to_currency" json:"to"`
+	Rate string `db:"rate" json:"rate"`
+	Date string `db:"effective_date" json:"date"`
+}
+
+type FxRepository interface {
+	GetBaseCurrency() (string, error)
+	SetBaseCurrency(currency string) error
+	SetRates(rates []FxRate) error
+	GetRates() ([]FxRate, error)
+}
+
+func NewFxRepository() FxRepository {
+	db, err := GetDB()
+	if err != nil {
+		panic(err)
+	}
+	return &fxRepository{db: db}
+}
+
+func (r *fxRepository) GetBaseCurrency() (string, error) {
+	return baseCurrency(r.db)
+}
+
+func baseCurrency(db *sqlx.DB) (string, error) {
+	var currency string
+	err := db.Get(&currency, "SELECT value FROM settings WHERE key = 'base_currency'")
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultBaseCurrency, nil
+	}
+	return currency, err
+}
+
+func (r *fxRepository) SetBaseCurrency(currency string) error {
+	return journal(r.db, "set base currency to "+currency, func(tx *sqlx.Tx) error {
+		// an upsert instead of INSERT OR REPLACE so the journal records the previous value
+		_, err := tx.Exec("INSERT INTO settings (key, value) VALUES ('base_currency', ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value", currency)
+		return err
+	})
+}
+
+// SetRates upserts all rates in a single transaction
+func (r *fxRepository) SetRates(rates []FxRate) error {
+	description := fmt.Sprintf("set %d exchange rates", len(rates))
+	if len(rates) == 1 {
+		description = fmt.Sprintf("set exchange rate %s/%s", rates[0].From, rates[0].To)
+	}
+	return journal(r.db, description, func(tx *sqlx.Tx) error {
+		for _, rate := range rates {
+			if _, ok := new(big.Rat).SetString(rate.Rate); !ok {
+				return fmt.Errorf("invalid rate %q", rate.Rate)
+			}
+			_, err := tx.Exec(
+				`INSERT INTO fx_rates (from_currency, to_currency, rate, effective_date) VALUES (?, ?, ?, ?)
+				ON CONFLICT (from_currency, to_currency, effective_date) DO UPDATE SET rate = excluded.rate`,
+				rate.From, rate.To, rate.Rate, rate.Date,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *fxRepository) GetRates() ([]FxRate, error) {
+	var rates []FxRate
+	err := r.db.Select(&rates, "SELECT from_currency, to_currency, rate, effective_date FROM fx_rates ORDER BY from_currency, to_currency, effective_date")
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+type datedRate struct {
+	date string
+	rate *big.Rat
+}
+
+// RateTable looks up the rate effective on a given date, falling back to the inverse of the
+// opposite pair when only that one is known
+type RateTable struct {
+	rates map[[2]string][]datedRate
+}
+
+func NewRateTable(rates []FxRate) RateTable {
+	t := RateTable{rates: map[[2]string][]datedRate{}}
+	for _, rate := range rates {
+		r, ok := new(big.Rat).SetString(rate.Rate)
+		if !ok || r.Sign() <= 0 {
+			continue
+		}
+		key := [2]string{rate.From, rate.To}
+		t.rates[key] = append(t.rates[key], datedRate{date: rate.Date, rate: r})
+	}
+	for key := range t.rates {
+		sort.Slice(t.rates[key], func(i, j int) bool {
+			return t.rates[key][i].date < t.rates[key][j].date
+		})
+	}
+	return t
+}
+
+// latest returns the most recent rate of the pair effective on date
+func (t RateTable) latest(from string, to string, date string) (datedRate, bool) {
+	var found datedRate
+	ok := false
+	for _, r := range t.rates[[2]string{from, to}] {
+		if r.date > date {
+			break
+		}
+		found, ok = r, true
+	}
+	return found, ok
+}
+
+// Rate returns how many units of to one unit of from is worth on date (YYYY-MM-DD)
+func (t RateTable) Rate(from string, to string, date string) (*big.Rat, bool) {
+	if from == to {
+		return big.NewRat(1, 1), true
+	}
+	direct, hasDirect := t.latest(from, to, date)
+	inverse, hasInverse := t.latest(to, from, date)
+	switch {
+	case hasDirect && (!hasInverse || direct.date >= inverse.date):
+		return direct.rate, true
+	case hasInverse:
+		return new(big.Rat).Inv(inverse.rate), true
+	}
+	return nil, false
+}
+
+// Convert converts amount from one currency to another using the rate effective on date
+func (t RateTable) Convert(amount money.Money, from string, to string, date string) (money.Money, bool) {
+	rate, ok := t.Rate(from, to, date)
+	if !ok {
+		return 0, false
+	}
+	return amount.Mul(rate), true
+}
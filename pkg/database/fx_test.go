@@ -0,0 +1,37 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+func TestRateTable(t *testing.T) {
+	table := database.NewRateTable([]database.FxRate{
+		{From: "EUR", To: "USD", Rate: "1.10", Date: "2024-01-01"},
+		{From: "EUR", To: "USD", Rate: "1.20", Date: "2024-02-01"},
+		{From: "USD", To: "GBP", Rate: "0.80", Date: "2024-01-01"},
+	})
+	tests := []struct {
+		amount money.Money
+		from   string
+		to     string
+		date   string
+		want   money.Money
+		ok     bool
+	}{
+		{1000, "EUR", "USD", "2024-01-15", 1100, true},
+		{1000, "EUR", "USD", "2024-02-01", 1200, true},
+		{1000, "EUR", "USD", "2023-12-31", 0, false},
+		{1000, "GBP", "USD", "2024-03-01", 1250, true},
+		{1000, "EUR", "GBP", "2024-03-01", 0, false},
+		{1000, "JPY", "JPY", "2024-03-01", 1000, true},
+	}
+	for _, tt := range tests {
+		got, ok := table.Convert(tt.amount, tt.from, tt.to, tt.date)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Convert(%d, %s, %s, %s) = %d, %v; want %d, %v", tt.amount, tt.from, tt.to, tt.date, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// number of operations kept in the journal, older ones can no longer be undone
+const journalSize = 100
+
+type journalRepository struct {
+	db *sqlx.DB
+}
+
+// Operation is a mutation recorded in the journal, undone operations can be redone until
+// the next operation is recorded
+type Operation struct {
+	ID          int    `db:"id" json:"id"`
+	Description string `db:"description" json:"description"`
+	Changes     int    `db:"changes" json:"changes"`
+	Undone      bool   `db:"undone" json:"undone"`
+	CreatedAt   string `db:"created_at" json:"created_at"`
+}
+
+// change is a row image pair written by the journal triggers, Before is NULL for inserts
+// and After for deletes
+type change struct {
+	Table  string  `db:"table_name"`
+	Before *string `db:"before"`
+	After  *string `db:"after"`
+}
+
+type JournalRepository interface {
+	// GetOperations returns the latest operations, newest first, all of them when limit is negative
+	GetOperations(limit int) ([]Operation, error)
+	// Undo reverts the last n operations that are not undone, newest first
+	Undo(n int) ([]Operation, error)
+	// Redo replays the last n undone operations, oldest first
+	Redo(n int) ([]Operation, error)
+}
+
+func NewJournalRepository() JournalRepository {
+	db, err := GetDB()
+	if err != nil {
+		panic(err)
+	}
+	return &journalRepository{db: db}
+}
+
+// journal runs fn in a sql transaction and records the rows it changes as one operation.
+// Recording an operation discards the undone ones.
+func journal(db *sqlx.DB, description string, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec("INSERT INTO operations (description) VALUES (?)", description)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE journal SET operation_id = ?", id)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE journal SET operation_id = NULL")
+	if err != nil {
+		return err
+	}
+	var changes int
+	err = tx.Get(&changes, "SELECT COUNT(*) FROM operation_changes WHERE operation_id = ?", id)
+	if err != nil {
+		return err
+	}
+	if changes == 0 {
+		_, err = tx.Exec("DELETE FROM operations WHERE id = ?", id)
+	} else {
+		err = deleteOperations(tx, "undone OR id <= ?", id-journalSize)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func deleteOperations(tx *sqlx.Tx, where string, args ...interface{}) error {
+	_, err := tx.Exec("DELETE FROM operation_changes WHERE operation_id IN (SELECT id FROM operations WHERE "+where+")", args...)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM operations WHERE "+where, args...)
+	return err
+}
+
+func (r *journalRepository) GetOperations(limit int) ([]Operation, error) {
+	var operations []Operation
+	err := r.db.Select(&operations, `
+		SELECT o.id, o.description, o.undone, o.created_at, COUNT(c.id) AS changes
+		FROM operations o
+		LEFT JOIN operation_changes c ON c.operation_id = o.id
+		GROUP BY o.id
+		ORDER BY o.id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+func (r *journalRepository) Undo(n int) ([]Operation, error) {
+	return r.replay(n, true)
+}
+
+func (r *journalRepository) Redo(n int) ([]Operation, error) {
+	return r.replay(n, false)
+}
+
+// replay reverts (undo) or reapplies the changes of n operations in a single sql
+// transaction, nothing changes when one of them conflicts with the current data
+func (r *journalRepository) replay(n int, undo bool) ([]Operation, error) {
+	query := "SELECT id, description, undone, created_at FROM operations WHERE undone = 0 ORDER BY id DESC LIMIT ?"
+	if !undo {
+		query = "SELECT id, description, undone, created_at FROM operations WHERE undone = 1 ORDER BY id ASC LIMIT ?"
+	}
+	var operations []Operation
+	err := r.db.Select(&operations, query, n)
+	if err != nil {
+		return nil, err
+	}
+	if len(operations) == 0 {
+		return nil, nil
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	// changes are reverted in reverse order
+	order := "ASC"
+	if undo {
+		order = "DESC"
+	}
+	columns := map[string][]column{}
+	for i, operation := range operations {
+		var changes []change
+		err := tx.Select(&changes, "SELECT table_name, before, after FROM operation_changes WHERE operation_id = ? ORDER BY id "+order, operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range changes {
+			if _, ok := columns[c.Table]; !ok {
+				columns[c.Table], err = tableColumns(tx, c.Table)
+				if err != nil {
+					return nil, err
+				}
+			}
+			from, to := c.Before, c.After
+			if undo {
+				from, to = to, from
+			}
+			if err := applyChange(tx, c.Table, columns[c.Table], from, to); err != nil {
+				verb := "redone"
+				if undo {
+					verb = "undone"
+				}
+				return nil, fmt.Errorf("operation %d (%s) cannot be %s: %w", operation.ID, operation.Description, verb, err)
+			}
+		}
+		_, err = tx.Exec("UPDATE operations SET undone = ? WHERE id = ?", undo, operation.ID)
+		if err != nil {
+			return nil, err
+		}
+		operations[i].Changes = len(changes)
+		operations[i].Undone = undo
+	}
+	return operations, tx.Commit()
+}
+
+type column struct {
+	Name string `db:"name"`
+	PK   int    `db:"pk"`
+}
+
+func tableColumns(tx *sqlx.Tx, table string) ([]column, error) {
+	var columns []column
+	err := tx.Select(&columns, "SELECT name, pk FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s no longer exists", table)
+	}
+	return columns, nil
+}
+
+// applyChange turns the row image from into to, a nil from inserts the row and a nil to
+// deletes it. Columns of the images that the table no longer has are ignored.
+func applyChange(tx *sqlx.Tx, table string, columns []column, from *string, to *string) error {
+	var before, after map[string]interface{}
+	var err error
+	if from != nil {
+		if before, err = decodeImage(*from); err != nil {
+			return err
+		}
+	}
+	if to != nil {
+		if after, err = decodeImage(*to); err != nil {
+			return err
+		}
+	}
+	var names, keys []string
+	for _, c := range columns {
+		image := after
+		if image == nil {
+			image = before
+		}
+		if _, ok := image[c.Name]; !ok {
+			continue
+		}
+		names = append(names, c.Name)
+		if c.PK > 0 {
+			keys = append(keys, c.Name)
+		}
+	}
+	if before == nil {
+		values := make([]interface{}, len(names))
+		for i, name := range names {
+			values[i] = after[name]
+		}
+		_, err := tx.Exec(
+			fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quote(table), quoteAll(names), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")),
+			values...,
+		)
+		if err != nil {
+			return fmt.Errorf("%s row %s: %w", table, describeKey(keys, after), err)
+		}
+		return nil
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("table %s has no primary key", table)
+	}
+	var sets, where []string
+	var values, args []interface{}
+	for _, name := range names {
+		if after != nil {
+			sets = append(sets, quote(name)+" = ?")
+			values = append(values, after[name])
+		}
+	}
+	for _, key := range keys {
+		where = append(where, quote(key)+" IS ?")
+		args = append(args, before[key])
+	}
+	var res sql.Result
+	if after == nil {
+		res, err = tx.Exec("DELETE FROM "+quote(table)+" WHERE "+strings.Join(where, " AND "), args...)
+	} else {
+		res, err = tx.Exec("UPDATE "+quote(table)+" SET "+strings.Join(sets, ", ")+" WHERE "+strings.Join(where, " AND "), append(values, args...)...)
+	}
+	if err != nil {
+		return fmt.Errorf("%s row %s: %w", table, describeKey(keys, before), err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return fmt.Errorf("%s row %s has changed since", table, describeKey(keys, before))
+	}
+	return nil
+}
+
+// decodeImage keeps integers as int64 so amounts and ids are written back unchanged
+func decodeImage(image string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewBufferString(image))
+	decoder.UseNumber()
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil {
+		return nil, errors.New("invalid row image in the journal")
+	}
+	for name, value := range row {
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if i, err := number.Int64(); err == nil {
+			row[name] = i
+		} else if f, err := number.Float64(); err == nil {
+			row[name] = f
+		}
+	}
+	return row, nil
+}
+
+func describeKey(keys []string, image map[string]interface{}) string {
+	var parts []string
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, image[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func quote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func quoteAll(identifiers []string) string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = quote(identifier)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,113 @@
+package database_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+func countTransactions(t *testing.T) int {
+	n, err := database.NewTransactionRepository().GetTransactionCountWithConfig(database.TransactionConfig{Page: 1, Limit: 1, All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestUndoAndRedo(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	transactions := database.NewTransactionRepository()
+	journal := database.NewJournalRepository()
+	_, err := transactions.CreateTransaction(database.Transaction{
+		Type: "expense", Description: "coffee", Amount: money.Money(350), Category: "Food", Tags: []string{"morning"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := money.Money(400)
+	if err := transactions.UpdateTransaction(database.UpdateConfig{ID: 1, Amount: &amount}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := transactions.DeleteTransactions(database.DeleteConfig{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countTransactions(t); n != 0 {
+		t.Fatal("expected no transactions, got", n)
+	}
+	operations, err := journal.Undo(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operations) != 2 || operations[0].Description != "move all transactions to the trash" {
+		t.Fatal("expected the remove and the edit to be undone, got", operations)
+	}
+	restored, err := transactions.GetTransactionsWithConfig(database.TransactionConfig{Page: 1, Limit: 1, All: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored) != 1 || restored[0].Amount != 350 || restored[0].Category != "Food" || len(restored[0].Tags) != 1 {
+		t.Fatal("expected the original coffee transaction, got", restored)
+	}
+	if _, err := journal.Redo(2); err != nil {
+		t.Fatal(err)
+	}
+	if n := countTransactions(t); n != 0 {
+		t.Error("expected the remove to be redone, got", n, "transactions")
+	}
+	if operations, _ := journal.Redo(1); len(operations) != 0 {
+		t.Error("expected nothing to redo, got", operations)
+	}
+	// a new change discards what was undone
+	journal.Undo(1)
+	if err := database.NewAccountRepository().CreateAccount("savings"); err != nil {
+		t.Fatal(err)
+	}
+	if operations, _ := journal.Redo(1); len(operations) != 0 {
+		t.Error("expected the undone remove to be discarded, got", operations)
+	}
+}
+
+func TestUndoRecurringPost(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	recurring := database.NewRecurringRepository()
+	journal := database.NewJournalRepository()
+	_, err := recurring.CreateRule(database.RecurringRule{
+		Type: "expense", Description: "rent", Amount: money.Money(90000), Currency: "USD",
+		Schedule: database.Schedule{Frequency: "month", Interval: 1, StartDate: "2024-01-01"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted, err := recurring.AutoPost("2024-03-15"); err != nil || posted != 3 {
+		t.Fatal("expected 3 occurrences to be posted, got", posted, err)
+	}
+	if _, err := journal.Undo(1); err != nil {
+		t.Fatal(err)
+	}
+	// the next command posts before it runs, the undone posting must stay undone
+	if posted, err := recurring.AutoPost("2024-03-15"); err != nil || posted != 0 {
+		t.Error("expected nothing to be posted after undo, got", posted, err)
+	}
+	if n := countTransactions(t); n != 0 {
+		t.Error("expected no transactions after undo, got", n)
+	}
+	if operations, err := journal.Redo(1); err != nil || len(operations) != 1 {
+		t.Fatal("expected the posting to be redone, got", operations, err)
+	}
+	if n := countTransactions(t); n != 3 {
+		t.Error("expected 3 transactions after redo, got", n)
+	}
+	// an explicit run posts regardless
+	journal.Undo(1)
+	if posted, err := recurring.Post("2024-03-15"); err != nil || posted != 3 {
+		t.Error("expected acc recurring run to post again, got", posted, err)
+	}
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// migrations bring the version 0 schema (schema.sql) up to date. They are applied in order
+// and tracked with PRAGMA user_version, NNNN_name.up.sql moves a database from version
+// NNNN-1 to NNNN and NNNN_name.down.sql moves it back
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationPattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrations returns the embedded migrations ordered by version
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		b, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if match[3] == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+	var migrations []Migration
+	for version := 1; version <= len(byVersion); version++ {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migration %04d is missing", version)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	return migrations, nil
+}
+
+// SchemaVersion is the version of the last migration applied to the database
+func SchemaVersion() (int, error) {
+	db, err := GetDB()
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	err = db.Get(&version, "PRAGMA user_version")
+	return version, err
+}
+
+// Migrate applies up or down migrations until the database is at version target
+func Migrate(target int) error {
+	db, err := GetDB()
+	if err != nil {
+		return err
+	}
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	return migrate(db, migrations, target)
+}
+
+// migrate runs every migration in its own sql transaction, a failed migration leaves the
+// database at the version before it
+func migrate(db *sqlx.DB, migrations []Migration, target int) error {
+	if target < 0 || target > len(migrations) {
+		return fmt.Errorf("unknown schema version %d, expected 0 to %d", target, len(migrations))
+	}
+	var version int
+	err := db.Get(&version, "PRAGMA user_version")
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this version of acc supports (%d)", version, len(migrations))
+	}
+	for version != target {
+		var m Migration
+		var script string
+		next := version + 1
+		if target > version {
+			m = migrations[version]
+			script = m.Up
+		} else {
+			m = migrations[version-1]
+			script = m.Down
+			next = version - 1
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(script)
+		if err == nil {
+			_, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", next))
+		}
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			tx.Rollback()
+			slog.Error("DB: failed to migrate database schema", "Migration", m.Version, "Error", err.Error())
+			// go-sqlite3 leaves FTS5 out unless it is built with the sqlite_fts5 tag
+			if strings.Contains(err.Error(), "no such module: fts5") {
+				return fmt.Errorf("migration %04d_%s failed: %w. rebuild acc with -tags sqlite_fts5", m.Version, m.Name, err)
+			}
+			return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+		}
+		version = next
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package database_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestMigrationsAreComplete(t *testing.T) {
+	migrations, err := database.Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("expected migration %d at position %d, got %d", i+1, i, m.Version)
+		}
+	}
+}
+
+func TestMigrateDownAndUp(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	migrations, _ := database.Migrations()
+	for _, target := range []int{len(migrations), 0, len(migrations)} {
+		if err := database.Migrate(target); err != nil {
+			t.Fatalf("migrating to %d: %v", target, err)
+		}
+		version, err := database.SchemaVersion()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version != target {
+			t.Errorf("expected version %d, got %d", target, version)
+		}
+	}
+	if err := database.Migrate(len(migrations) + 1); err == nil {
+		t.Error("expected an error for an unknown version")
+	}
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS transactions_occurred_at_idx;
+ALTER TABLE transactions DROP COLUMN occurred_at;
@@ -0,0 +1,4 @@
+-- transactions carry their own date
+ALTER TABLE transactions ADD COLUMN occurred_at TIMESTAMP;
+UPDATE transactions SET occurred_at = created_at;
+CREATE INDEX IF NOT EXISTS transactions_occurred_at_idx ON transactions (occurred_at);
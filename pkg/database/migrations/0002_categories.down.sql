@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_category_id_idx;
+ALTER TABLE transactions DROP COLUMN category_id;
+DROP TABLE categories;
@@ -0,0 +1,9 @@
+-- hierarchical categories
+CREATE TABLE IF NOT EXISTS categories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	parent_id INTEGER REFERENCES categories (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS categories_parent_name_idx ON categories (COALESCE(parent_id, 0), name);
+ALTER TABLE transactions ADD COLUMN category_id INTEGER REFERENCES categories (id);
+CREATE INDEX IF NOT EXISTS transactions_category_id_idx ON transactions (category_id);
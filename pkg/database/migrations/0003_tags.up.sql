@@ -0,0 +1,11 @@
+-- free-form tags
+CREATE TABLE IF NOT EXISTS tags (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS transaction_tags (
+	transaction_id INTEGER NOT NULL REFERENCES transactions (id),
+	tag_id INTEGER NOT NULL REFERENCES tags (id),
+	PRIMARY KEY (transaction_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS transaction_tags_tag_id_idx ON transaction_tags (tag_id);
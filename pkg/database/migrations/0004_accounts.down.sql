@@ -0,0 +1,24 @@
+-- transfers cannot be represented without accounts, the CHECK constraint fails while any exist
+CREATE TABLE transactions_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL CHECK (type IN ('income', 'expense')),
+	description TEXT NOT NULL,
+	amount FLOAT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	occurred_at TIMESTAMP,
+	category_id INTEGER REFERENCES categories (id)
+);
+INSERT INTO transactions_old (id, type, description, amount, created_at, updated_at, occurred_at, category_id)
+	SELECT id, type, description, amount, created_at, updated_at, occurred_at, category_id FROM transactions;
+DELETE FROM sqlite_sequence WHERE name = 'transactions_old';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'transactions_old', seq FROM sqlite_sequence WHERE name = 'transactions';
+DROP TABLE transactions;
+ALTER TABLE transactions_old RENAME TO transactions;
+CREATE INDEX IF NOT EXISTS transactions_type_idx ON transactions (type);
+CREATE INDEX IF NOT EXISTS transactions_created_at_idx ON transactions (created_at);
+CREATE INDEX IF NOT EXISTS transactions_amount_idx ON transactions (amount);
+CREATE INDEX IF NOT EXISTS transactions_description_idx ON transactions (description);
+CREATE INDEX IF NOT EXISTS transactions_occurred_at_idx ON transactions (occurred_at);
+CREATE INDEX IF NOT EXISTS transactions_category_id_idx ON transactions (category_id);
+DROP TABLE accounts;
@@ -0,0 +1,27 @@
+CREATE TABLE transactions_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'transfer')),
+	description TEXT NOT NULL,
+	amount FLOAT NOT NULL,
+	occurred_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	category_id INTEGER REFERENCES categories (id),
+	account_id INTEGER NOT NULL DEFAULT 1 REFERENCES accounts (id),
+	to_account_id INTEGER REFERENCES accounts (id),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK ((type = 'transfer') = (to_account_id IS NOT NULL))
+);
+INSERT INTO transactions_old (id, type, description, amount, occurred_at, category_id, account_id, to_account_id, created_at, updated_at)
+	SELECT id, type, description, amount / 100.0, occurred_at, category_id, account_id, to_account_id, created_at, updated_at FROM transactions;
+DELETE FROM sqlite_sequence WHERE name = 'transactions_old';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'transactions_old', seq FROM sqlite_sequence WHERE name = 'transactions';
+DROP TABLE transactions;
+ALTER TABLE transactions_old RENAME TO transactions;
+CREATE INDEX IF NOT EXISTS transactions_type_idx ON transactions (type);
+CREATE INDEX IF NOT EXISTS transactions_created_at_idx ON transactions (created_at);
+CREATE INDEX IF NOT EXISTS transactions_occurred_at_idx ON transactions (occurred_at);
+CREATE INDEX IF NOT EXISTS transactions_category_id_idx ON transactions (category_id);
+CREATE INDEX IF NOT EXISTS transactions_account_id_idx ON transactions (account_id);
+CREATE INDEX IF NOT EXISTS transactions_to_account_id_idx ON transactions (to_account_id);
+CREATE INDEX IF NOT EXISTS transactions_amount_idx ON transactions (amount);
+CREATE INDEX IF NOT EXISTS transactions_description_idx ON transactions (description);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS transactions_currency_idx;
+ALTER TABLE transactions DROP COLUMN currency;
+DROP TABLE fx_rates;
+DROP TABLE settings;
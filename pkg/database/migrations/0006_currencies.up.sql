@@ -0,0 +1,16 @@
+-- multi-currency transactions and exchange rates
+ALTER TABLE transactions ADD COLUMN currency TEXT NOT NULL DEFAULT 'USD';
+CREATE INDEX IF NOT EXISTS transactions_currency_idx ON transactions (currency);
+CREATE TABLE IF NOT EXISTS settings (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+INSERT OR IGNORE INTO settings (key, value) VALUES ('base_currency', 'USD');
+CREATE TABLE IF NOT EXISTS fx_rates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	from_currency TEXT NOT NULL,
+	to_currency TEXT NOT NULL,
+	rate TEXT NOT NULL,
+	effective_date TEXT NOT NULL,
+	UNIQUE (from_currency, to_currency, effective_date)
+);
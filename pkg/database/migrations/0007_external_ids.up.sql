@@ -0,0 +1,3 @@
+-- id assigned by the bank (OFX FITID) so statements can be imported more than once
+ALTER TABLE transactions ADD COLUMN external_id TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_external_id_idx ON transactions (account_id, external_id) WHERE external_id IS NOT NULL;
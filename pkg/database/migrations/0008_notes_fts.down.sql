@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS transactions_fts_insert;
+DROP TRIGGER IF EXISTS transactions_fts_delete;
+DROP TRIGGER IF EXISTS transactions_fts_update;
+DROP TABLE transactions_fts;
+ALTER TABLE transactions DROP COLUMN note;
@@ -0,0 +1,15 @@
+-- notes and a full-text index over descriptions and notes, kept in sync by triggers.
+-- migrations that rebuild the transactions table have to create the triggers again
+ALTER TABLE transactions ADD COLUMN note TEXT NOT NULL DEFAULT '';
+CREATE VIRTUAL TABLE IF NOT EXISTS transactions_fts USING fts5 (description, note, content = 'transactions', content_rowid = 'id');
+CREATE TRIGGER IF NOT EXISTS transactions_fts_insert AFTER INSERT ON transactions BEGIN
+	INSERT INTO transactions_fts (rowid, description, note) VALUES (new.id, new.description, new.note);
+END;
+CREATE TRIGGER IF NOT EXISTS transactions_fts_delete AFTER DELETE ON transactions BEGIN
+	INSERT INTO transactions_fts (transactions_fts, rowid, description, note) VALUES ('delete', old.id, old.description, old.note);
+END;
+CREATE TRIGGER IF NOT EXISTS transactions_fts_update AFTER UPDATE OF description, note ON transactions BEGIN
+	INSERT INTO transactions_fts (transactions_fts, rowid, description, note) VALUES ('delete', old.id, old.description, old.note);
+	INSERT INTO transactions_fts (rowid, description, note) VALUES (new.id, new.description, new.note);
+END;
+INSERT INTO transactions_fts (transactions_fts) VALUES ('rebuild');
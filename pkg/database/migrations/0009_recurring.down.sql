@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS transactions_recurring_idx;
+ALTER TABLE transactions DROP COLUMN recurring_id;
+DROP TABLE recurring_rules;
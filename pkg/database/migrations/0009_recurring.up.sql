@@ -0,0 +1,24 @@
+-- recurring transactions, occurrences up to last_date have been posted
+CREATE TABLE IF NOT EXISTS recurring_rules (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL CHECK (type IN ('income', 'expense', 'transfer')),
+	description TEXT NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	amount INTEGER NOT NULL,
+	currency TEXT NOT NULL,
+	category_id INTEGER REFERENCES categories (id),
+	account_id INTEGER NOT NULL DEFAULT 1 REFERENCES accounts (id),
+	to_account_id INTEGER REFERENCES accounts (id),
+	tags TEXT NOT NULL DEFAULT '',
+	frequency TEXT NOT NULL CHECK (frequency IN ('day', 'week', 'month', 'year')),
+	interval INTEGER NOT NULL DEFAULT 1 CHECK (interval > 0),
+	on_day INTEGER,
+	start_date TEXT NOT NULL,
+	end_date TEXT,
+	last_date TEXT,
+	paused INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	CHECK ((type = 'transfer') = (to_account_id IS NOT NULL))
+);
+ALTER TABLE transactions ADD COLUMN recurring_id INTEGER REFERENCES recurring_rules (id);
+CREATE UNIQUE INDEX IF NOT EXISTS transactions_recurring_idx ON transactions (recurring_id, occurred_at) WHERE recurring_id IS NOT NULL;
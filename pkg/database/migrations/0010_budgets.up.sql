@@ -0,0 +1,10 @@
+-- spending limits per category in the base currency, rollover accumulates from start_date
+CREATE TABLE IF NOT EXISTS budgets (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	category_id INTEGER NOT NULL UNIQUE REFERENCES categories (id),
+	amount INTEGER NOT NULL CHECK (amount > 0),
+	period TEXT NOT NULL CHECK (period IN ('week', 'month', 'year')),
+	rollover INTEGER NOT NULL DEFAULT 0,
+	start_date TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
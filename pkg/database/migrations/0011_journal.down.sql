@@ -0,0 +1,30 @@
+DROP TRIGGER transactions_journal_insert;
+DROP TRIGGER transactions_journal_update;
+DROP TRIGGER transactions_journal_delete;
+DROP TRIGGER transaction_tags_journal_insert;
+DROP TRIGGER transaction_tags_journal_update;
+DROP TRIGGER transaction_tags_journal_delete;
+DROP TRIGGER tags_journal_insert;
+DROP TRIGGER tags_journal_update;
+DROP TRIGGER tags_journal_delete;
+DROP TRIGGER categories_journal_insert;
+DROP TRIGGER categories_journal_update;
+DROP TRIGGER categories_journal_delete;
+DROP TRIGGER accounts_journal_insert;
+DROP TRIGGER accounts_journal_update;
+DROP TRIGGER accounts_journal_delete;
+DROP TRIGGER settings_journal_insert;
+DROP TRIGGER settings_journal_update;
+DROP TRIGGER settings_journal_delete;
+DROP TRIGGER fx_rates_journal_insert;
+DROP TRIGGER fx_rates_journal_update;
+DROP TRIGGER fx_rates_journal_delete;
+DROP TRIGGER recurring_rules_journal_insert;
+DROP TRIGGER recurring_rules_journal_update;
+DROP TRIGGER recurring_rules_journal_delete;
+DROP TRIGGER budgets_journal_insert;
+DROP TRIGGER budgets_journal_update;
+DROP TRIGGER budgets_journal_delete;
+DROP TABLE journal;
+DROP TABLE operation_changes;
+DROP TABLE operations;
@@ -0,0 +1,193 @@
+-- every change to the journaled tables made while journal.operation_id is set is recorded
+-- with its before and after row images (NULL for inserts and deletes) so acc undo and acc
+-- redo can reverse and replay it
+CREATE TABLE IF NOT EXISTS operations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	description TEXT NOT NULL,
+	undone INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS operation_changes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	operation_id INTEGER NOT NULL REFERENCES operations (id),
+	table_name TEXT NOT NULL,
+	before TEXT,
+	after TEXT
+);
+CREATE INDEX IF NOT EXISTS operation_changes_operation_id_idx ON operation_changes (operation_id);
+-- single row holding the operation being recorded, NULL outside of one
+CREATE TABLE IF NOT EXISTS journal (
+	operation_id INTEGER
+);
+INSERT INTO journal (operation_id) VALUES (NULL);
+CREATE TRIGGER IF NOT EXISTS transactions_journal_insert AFTER INSERT ON transactions
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'transactions',
+		json_object('id', NEW.id, 'type', NEW.type, 'description', NEW.description, 'amount', NEW.amount, 'occurred_at', NEW.occurred_at, 'category_id', NEW.category_id, 'account_id', NEW.account_id, 'to_account_id', NEW.to_account_id, 'created_at', NEW.created_at, 'updated_at', NEW.updated_at, 'currency', NEW.currency, 'external_id', NEW.external_id, 'note', NEW.note, 'recurring_id', NEW.recurring_id));
+END;
+CREATE TRIGGER IF NOT EXISTS transactions_journal_update AFTER UPDATE ON transactions
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'transactions',
+		json_object('id', OLD.id, 'type', OLD.type, 'description', OLD.description, 'amount', OLD.amount, 'occurred_at', OLD.occurred_at, 'category_id', OLD.category_id, 'account_id', OLD.account_id, 'to_account_id', OLD.to_account_id, 'created_at', OLD.created_at, 'updated_at', OLD.updated_at, 'currency', OLD.currency, 'external_id', OLD.external_id, 'note', OLD.note, 'recurring_id', OLD.recurring_id),
+		json_object('id', NEW.id, 'type', NEW.type, 'description', NEW.description, 'amount', NEW.amount, 'occurred_at', NEW.occurred_at, 'category_id', NEW.category_id, 'account_id', NEW.account_id, 'to_account_id', NEW.to_account_id, 'created_at', NEW.created_at, 'updated_at', NEW.updated_at, 'currency', NEW.currency, 'external_id', NEW.external_id, 'note', NEW.note, 'recurring_id', NEW.recurring_id));
+END;
+CREATE TRIGGER IF NOT EXISTS transactions_journal_delete AFTER DELETE ON transactions
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'transactions',
+		json_object('id', OLD.id, 'type', OLD.type, 'description', OLD.description, 'amount', OLD.amount, 'occurred_at', OLD.occurred_at, 'category_id', OLD.category_id, 'account_id', OLD.account_id, 'to_account_id', OLD.to_account_id, 'created_at', OLD.created_at, 'updated_at', OLD.updated_at, 'currency', OLD.currency, 'external_id', OLD.external_id, 'note', OLD.note, 'recurring_id', OLD.recurring_id));
+END;
+CREATE TRIGGER IF NOT EXISTS transaction_tags_journal_insert AFTER INSERT ON transaction_tags
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'transaction_tags',
+		json_object('transaction_id', NEW.transaction_id, 'tag_id', NEW.tag_id));
+END;
+CREATE TRIGGER IF NOT EXISTS transaction_tags_journal_update AFTER UPDATE ON transaction_tags
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'transaction_tags',
+		json_object('transaction_id', OLD.transaction_id, 'tag_id', OLD.tag_id),
+		json_object('transaction_id', NEW.transaction_id, 'tag_id', NEW.tag_id));
+END;
+CREATE TRIGGER IF NOT EXISTS transaction_tags_journal_delete AFTER DELETE ON transaction_tags
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'transaction_tags',
+		json_object('transaction_id', OLD.transaction_id, 'tag_id', OLD.tag_id));
+END;
+CREATE TRIGGER IF NOT EXISTS tags_journal_insert AFTER INSERT ON tags
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'tags',
+		json_object('id', NEW.id, 'name', NEW.name));
+END;
+CREATE TRIGGER IF NOT EXISTS tags_journal_update AFTER UPDATE ON tags
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'tags',
+		json_object('id', OLD.id, 'name', OLD.name),
+		json_object('id', NEW.id, 'name', NEW.name));
+END;
+CREATE TRIGGER IF NOT EXISTS tags_journal_delete AFTER DELETE ON tags
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'tags',
+		json_object('id', OLD.id, 'name', OLD.name));
+END;
+CREATE TRIGGER IF NOT EXISTS categories_journal_insert AFTER INSERT ON categories
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'categories',
+		json_object('id', NEW.id, 'name', NEW.name, 'parent_id', NEW.parent_id));
+END;
+CREATE TRIGGER IF NOT EXISTS categories_journal_update AFTER UPDATE ON categories
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'categories',
+		json_object('id', OLD.id, 'name', OLD.name, 'parent_id', OLD.parent_id),
+		json_object('id', NEW.id, 'name', NEW.name, 'parent_id', NEW.parent_id));
+END;
+CREATE TRIGGER IF NOT EXISTS categories_journal_delete AFTER DELETE ON categories
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'categories',
+		json_object('id', OLD.id, 'name', OLD.name, 'parent_id', OLD.parent_id));
+END;
+CREATE TRIGGER IF NOT EXISTS accounts_journal_insert AFTER INSERT ON accounts
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'accounts',
+		json_object('id', NEW.id, 'name', NEW.name, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS accounts_journal_update AFTER UPDATE ON accounts
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'accounts',
+		json_object('id', OLD.id, 'name', OLD.name, 'created_at', OLD.created_at),
+		json_object('id', NEW.id, 'name', NEW.name, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS accounts_journal_delete AFTER DELETE ON accounts
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'accounts',
+		json_object('id', OLD.id, 'name', OLD.name, 'created_at', OLD.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS settings_journal_insert AFTER INSERT ON settings
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'settings',
+		json_object('key', NEW.key, 'value', NEW.value));
+END;
+CREATE TRIGGER IF NOT EXISTS settings_journal_update AFTER UPDATE ON settings
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'settings',
+		json_object('key', OLD.key, 'value', OLD.value),
+		json_object('key', NEW.key, 'value', NEW.value));
+END;
+CREATE TRIGGER IF NOT EXISTS settings_journal_delete AFTER DELETE ON settings
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'settings',
+		json_object('key', OLD.key, 'value', OLD.value));
+END;
+CREATE TRIGGER IF NOT EXISTS fx_rates_journal_insert AFTER INSERT ON fx_rates
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'fx_rates',
+		json_object('id', NEW.id, 'from_currency', NEW.from_currency, 'to_currency', NEW.to_currency, 'rate', NEW.rate, 'effective_date', NEW.effective_date));
+END;
+CREATE TRIGGER IF NOT EXISTS fx_rates_journal_update AFTER UPDATE ON fx_rates
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'fx_rates',
+		json_object('id', OLD.id, 'from_currency', OLD.from_currency, 'to_currency', OLD.to_currency, 'rate', OLD.rate, 'effective_date', OLD.effective_date),
+		json_object('id', NEW.id, 'from_currency', NEW.from_currency, 'to_currency', NEW.to_currency, 'rate', NEW.rate, 'effective_date', NEW.effective_date));
+END;
+CREATE TRIGGER IF NOT EXISTS fx_rates_journal_delete AFTER DELETE ON fx_rates
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'fx_rates',
+		json_object('id', OLD.id, 'from_currency', OLD.from_currency, 'to_currency', OLD.to_currency, 'rate', OLD.rate, 'effective_date', OLD.effective_date));
+END;
+CREATE TRIGGER IF NOT EXISTS recurring_rules_journal_insert AFTER INSERT ON recurring_rules
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'recurring_rules',
+		json_object('id', NEW.id, 'type', NEW.type, 'description', NEW.description, 'note', NEW.note, 'amount', NEW.amount, 'currency', NEW.currency, 'category_id', NEW.category_id, 'account_id', NEW.account_id, 'to_account_id', NEW.to_account_id, 'tags', NEW.tags, 'frequency', NEW.frequency, 'interval', NEW.interval, 'on_day', NEW.on_day, 'start_date', NEW.start_date, 'end_date', NEW.end_date, 'last_date', NEW.last_date, 'paused', NEW.paused, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS recurring_rules_journal_update AFTER UPDATE ON recurring_rules
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'recurring_rules',
+		json_object('id', OLD.id, 'type', OLD.type, 'description', OLD.description, 'note', OLD.note, 'amount', OLD.amount, 'currency', OLD.currency, 'category_id', OLD.category_id, 'account_id', OLD.account_id, 'to_account_id', OLD.to_account_id, 'tags', OLD.tags, 'frequency', OLD.frequency, 'interval', OLD.interval, 'on_day', OLD.on_day, 'start_date', OLD.start_date, 'end_date', OLD.end_date, 'last_date', OLD.last_date, 'paused', OLD.paused, 'created_at', OLD.created_at),
+		json_object('id', NEW.id, 'type', NEW.type, 'description', NEW.description, 'note', NEW.note, 'amount', NEW.amount, 'currency', NEW.currency, 'category_id', NEW.category_id, 'account_id', NEW.account_id, 'to_account_id', NEW.to_account_id, 'tags', NEW.tags, 'frequency', NEW.frequency, 'interval', NEW.interval, 'on_day', NEW.on_day, 'start_date', NEW.start_date, 'end_date', NEW.end_date, 'last_date', NEW.last_date, 'paused', NEW.paused, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS recurring_rules_journal_delete AFTER DELETE ON recurring_rules
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'recurring_rules',
+		json_object('id', OLD.id, 'type', OLD.type, 'description', OLD.description, 'note', OLD.note, 'amount', OLD.amount, 'currency', OLD.currency, 'category_id', OLD.category_id, 'account_id', OLD.account_id, 'to_account_id', OLD.to_account_id, 'tags', OLD.tags, 'frequency', OLD.frequency, 'interval', OLD.interval, 'on_day', OLD.on_day, 'start_date', OLD.start_date, 'end_date', OLD.end_date, 'last_date', OLD.last_date, 'paused', OLD.paused, 'created_at', OLD.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS budgets_journal_insert AFTER INSERT ON budgets
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'budgets',
+		json_object('id', NEW.id, 'category_id', NEW.category_id, 'amount', NEW.amount, 'period', NEW.period, 'rollover', NEW.rollover, 'start_date', NEW.start_date, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS budgets_journal_update AFTER UPDATE ON budgets
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'budgets',
+		json_object('id', OLD.id, 'category_id', OLD.category_id, 'amount', OLD.amount, 'period', OLD.period, 'rollover', OLD.rollover, 'start_date', OLD.start_date, 'created_at', OLD.created_at),
+		json_object('id', NEW.id, 'category_id', NEW.category_id, 'amount', NEW.amount, 'period', NEW.period, 'rollover', NEW.rollover, 'start_date', NEW.start_date, 'created_at', NEW.created_at));
+END;
+CREATE TRIGGER IF NOT EXISTS budgets_journal_delete AFTER DELETE ON budgets
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'budgets',
+		json_object('id', OLD.id, 'category_id', OLD.category_id, 'amount', OLD.amount, 'period', OLD.period, 'rollover', OLD.rollover, 'start_date', OLD.start_date, 'created_at', OLD.created_at));
+END;
@@ -0,0 +1,27 @@
+-- the trash is emptied, transactions in it are removed for good
+DELETE FROM transaction_tags WHERE transaction_id IN (SELECT id FROM transactions WHERE deleted_at IS NOT NULL);
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+DROP TRIGGER transactions_journal_insert;
+DROP TRIGGER transactions_journal_update;
+DROP TRIGGER transactions_journal_delete;
+CREATE TRIGGER transactions_journal_insert AFTER INSERT ON transactions
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, after)
+	VALUES ((SELECT operation_id FROM journal), 'transactions',
+		json_object('id', NEW.id, 'type', NEW.type, 'description', NEW.description, 'amount', NEW.amount, 'occurred_at', NEW.occurred_at, 'category_id', NEW.category_id, 'account_id', NEW.account_id, 'to_account_id', NEW.to_account_id, 'created_at', NEW.created_at, 'updated_at', NEW.updated_at, 'currency', NEW.currency, 'external_id', NEW.external_id, 'note', NEW.note, 'recurring_id', NEW.recurring_id));
+END;
+CREATE TRIGGER transactions_journal_update AFTER UPDATE ON transactions
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before, after)
+	VALUES ((SELECT operation_id FROM journal), 'transactions',
+		json_object('id', OLD.id, 'type', OLD.type, 'description', OLD.description, 'amount', OLD.amount, 'occurred_at', OLD.occurred_at, 'category_id', OLD.category_id, 'account_id', OLD.account_id, 'to_account_id', OLD.to_account_id, 'created_at', OLD.created_at, 'updated_at', OLD.updated_at, 'currency', OLD.currency, 'external_id', OLD.external_id, 'note', OLD.note, 'recurring_id', OLD.recurring_id),
+		json_object('id', NEW.id, 'type', NEW.type, 'description', NEW.description, 'amount', NEW.amount, 'occurred_at', NEW.occurred_at, 'category_id', NEW.category_id, 'account_id', NEW.account_id, 'to_account_id', NEW.to_account_id, 'created_at', NEW.created_at, 'updated_at', NEW.updated_at, 'currency', NEW.currency, 'external_id', NEW.external_id, 'note', NEW.note, 'recurring_id', NEW.recurring_id));
+END;
+CREATE TRIGGER transactions_journal_delete AFTER DELETE ON transactions
+WHEN (SELECT operation_id FROM journal) IS NOT NULL BEGIN
+	INSERT INTO operation_changes (operation_id, table_name, before)
+	VALUES ((SELECT operation_id FROM journal), 'transactions',
+		json_object('id', OLD.id, 'type', OLD.type, 'description', OLD.description, 'amount', OLD.amount, 'occurred_at', OLD.occurred_at, 'category_id', OLD.category_id, 'account_id', OLD.account_id, 'to_account_id', OLD.to_account_id, 'created_at', OLD.created_at, 'updated_at', OLD.updated_at, 'currency', OLD.currency, 'external_id', OLD.external_id, 'note', OLD.note, 'recurring_id', OLD.recurring_id));
+END;
+DROP INDEX IF EXISTS transactions_deleted_at_idx;
+ALTER TABLE transactions DROP COLUMN deleted_at;
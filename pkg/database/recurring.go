@@ -0,0 +1,253 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/jmoiron/sqlx"
+)
+
+type recurringRepository struct {
+	db *sqlx.DB
+}
+
+// RecurringRule is a transaction template posted on every date of its schedule, occurrences
+// up to and including LastDate have been posted
+type RecurringRule struct {
+	ID          int         `db:"id" json:"id"`
+	Type        string      `db:"type" json:"type"`
+	Description string      `db:"description" json:"description"`
+	Note        string      `db:"note" json:"note,omitempty"`
+	Amount      money.Money `db:"amount" json:"amount"`
+	Currency    string      `db:"currency" json:"currency"`
+	CategoryID  *int        `db:"category_id" json:"category_id"`
+	Category    string      `db:"-" json:"category,omitempty"`
+	AccountID   int         `db:"account_id" json:"account_id"`
+	Account     string      `db:"-" json:"account"`
+	ToAccountID *int        `db:"to_account_id" json:"to_account_id"`
+	ToAccount   string      `db:"-" json:"to_account,omitempty"`
+	// Tags are stored comma separated, tag names cannot contain commas
+	Tags string `db:"tags" json:"tags"`
+	Schedule
+	LastDate  *string `db:"last_date" json:"last_date"`
+	Paused    bool    `db:"paused" json:"paused"`
+	CreatedAt string  `db:"created_at" json:"created_at"`
+}
+
+type RecurringRepository interface {
+	CreateRule(rule RecurringRule) (int, error)
+	GetRules() ([]RecurringRule, error)
+	DeleteRule(id int) error
+	PauseRule(id int) error
+	// ResumeRule skips the occurrences up to and including skipThrough
+	ResumeRule(id int, skipThrough string) error
+	// DueTransactions returns the transactions Post would book
+	DueTransactions(until string) ([]Transaction, error)
+	// Post books every occurrence due up to and including until, it is safe to run repeatedly
+	Post(until string) (int, error)
+	// AutoPost is Post for the posting done before every command, it posts nothing while the
+	// latest operation in the journal is an undone posting so acc undo of it sticks
+	AutoPost(until string) (int, error)
+}
+
+func NewRecurringRepository() RecurringRepository {
+	db, err := GetDB()
+	if err != nil {
+		panic(err)
+	}
+	return &recurringRepository{db: db}
+}
+
+func (r *recurringRepository) CreateRule(rule RecurringRule) (int, error) {
+	if rule.AccountID == 0 {
+		rule.AccountID = DefaultAccountID
+	}
+	var id int64
+	err := journal(r.db, fmt.Sprintf("add recurring transaction %q", rule.Description), func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(
+			`INSERT INTO recurring_rules (type, description, note, amount, currency, category_id, account_id, to_account_id, tags,
+				frequency, interval, on_day, start_date, end_date)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			rule.Type, rule.Description, rule.Note, rule.Amount, rule.Currency, rule.CategoryID, rule.AccountID, rule.ToAccountID, rule.Tags,
+			rule.Frequency, rule.Interval, rule.OnDay, rule.StartDate, rule.EndDate,
+		)
+		if err != nil {
+			return err
+		}
+		id, err = res.LastInsertId()
+		return err
+	})
+	return int(id), err
+}
+
+func (r *recurringRepository) GetRules() ([]RecurringRule, error) {
+	var rules []RecurringRule
+	err := r.db.Select(&rules, "SELECT * FROM recurring_rules ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	var categories []Category
+	err = r.db.Select(&categories, "SELECT id, name, parent_id FROM categories")
+	if err != nil {
+		return nil, err
+	}
+	paths := CategoryPaths(categories)
+	names, err := accountNames(r.db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		if rules[i].CategoryID != nil {
+			rules[i].Category = paths[*rules[i].CategoryID]
+		}
+		rules[i].Account = names[rules[i].AccountID]
+		if rules[i].ToAccountID != nil {
+			rules[i].ToAccount = names[*rules[i].ToAccountID]
+		}
+	}
+	return rules, nil
+}
+
+// DeleteRule keeps the posted transactions but unlinks them from the rule
+func (r *recurringRepository) DeleteRule(id int) error {
+	return journal(r.db, fmt.Sprintf("remove recurring transaction %d", id), func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("UPDATE transactions SET recurring_id = NULL WHERE recurring_id = ?", id)
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec("DELETE FROM recurring_rules WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+		return ruleFound(res, id)
+	})
+}
+
+func (r *recurringRepository) PauseRule(id int) error {
+	return journal(r.db, fmt.Sprintf("pause recurring transaction %d", id), func(tx *sqlx.Tx) error {
+		res, err := tx.Exec("UPDATE recurring_rules SET paused = 1 WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+		return ruleFound(res, id)
+	})
+}
+
+func (r *recurringRepository) ResumeRule(id int, skipThrough string) error {
+	return journal(r.db, fmt.Sprintf("resume recurring transaction %d", id), func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(
+			"UPDATE recurring_rules SET paused = 0, last_date = MAX(COALESCE(last_date, ''), ?) WHERE id = ?",
+			skipThrough, id,
+		)
+		if err != nil {
+			return err
+		}
+		return ruleFound(res, id)
+	})
+}
+
+func ruleFound(res sql.Result, id int) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("recurring transaction %d not found", id)
+	}
+	return nil
+}
+
+func (r *recurringRepository) DueTransactions(until string) ([]Transaction, error) {
+	rules, err := r.GetRules()
+	if err != nil {
+		return nil, err
+	}
+	var transactions []Transaction
+	for _, rule := range rules {
+		if rule.Paused {
+			continue
+		}
+		after := ""
+		if rule.LastDate != nil {
+			after = *rule.LastDate
+		}
+		for _, date := range rule.Occurrences(after, until) {
+			transactions = append(transactions, rule.transaction(date))
+		}
+	}
+	return transactions, nil
+}
+
+// transaction is the occurrence of the rule on date
+func (rule RecurringRule) transaction(date string) Transaction {
+	id := rule.ID
+	t := Transaction{
+		Type:        rule.Type,
+		Description: rule.Description,
+		Note:        rule.Note,
+		Amount:      rule.Amount,
+		Currency:    rule.Currency,
+		OccurredAt:  date + " 00:00:00",
+		CategoryID:  rule.CategoryID,
+		Category:    rule.Category,
+		AccountID:   rule.AccountID,
+		Account:     rule.Account,
+		ToAccountID: rule.ToAccountID,
+		ToAccount:   rule.ToAccount,
+		RecurringID: &id,
+	}
+	if rule.Tags != "" {
+		t.Tags = strings.Split(rule.Tags, ",")
+	}
+	return t
+}
+
+// postDescription is the journal description of a posting
+const postDescription = "post recurring transactions"
+
+func (r *recurringRepository) Post(until string) (int, error) {
+	transactions, err := r.DueTransactions(until)
+	if err != nil || len(transactions) == 0 {
+		return 0, err
+	}
+	posted := 0
+	err = journal(r.db, postDescription, func(tx *sqlx.Tx) error {
+		lastDates := map[int]string{}
+		for _, transaction := range transactions {
+			id, err := insertTransaction(tx, transaction)
+			if err != nil {
+				return fmt.Errorf("recurring transaction %d (%s): %w", *transaction.RecurringID, transaction.Description, err)
+			}
+			if id != 0 {
+				posted++
+			}
+			lastDates[*transaction.RecurringID] = transaction.OccurredAt[:len(ScheduleDateFormat)]
+		}
+		for id, date := range lastDates {
+			_, err := tx.Exec("UPDATE recurring_rules SET last_date = ? WHERE id = ?", date, id)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return posted, nil
+}
+
+func (r *recurringRepository) AutoPost(until string) (int, error) {
+	var latest []Operation
+	err := r.db.Select(&latest, "SELECT id, description, undone, created_at FROM operations ORDER BY id DESC LIMIT 1")
+	if err != nil {
+		return 0, err
+	}
+	// recording the next change discards the undone posting, after that it is due again
+	if len(latest) == 1 && latest[0].Undone && latest[0].Description == postDescription {
+		return 0, nil
+	}
+	return r.Post(until)
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layout of the dates stored for schedules
+const ScheduleDateFormat = "2006-01-02"
+
+var frequencies = map[string]string{
+	"day": "day", "daily": "day",
+	"week": "week", "weekly": "week",
+	"month": "month", "monthly": "month",
+	"year": "year", "yearly": "year",
+}
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Schedule repeats every Interval days, weeks, months or years from StartDate, modelled on
+// the FREQ, INTERVAL, BYMONTHDAY and BYDAY parts of an iCalendar RRULE. OnDay is the day
+// of the month (-1 for the last day) for monthly schedules and the ISO weekday (1 = monday)
+// for weekly ones, without it the day of StartDate is used.
+type Schedule struct {
+	Frequency string  `db:"frequency" json:"frequency"`
+	Interval  int     `db:"interval" json:"interval"`
+	OnDay     *int    `db:"on_day" json:"on_day"`
+	StartDate string  `db:"start_date" json:"start_date"`
+	EndDate   *string `db:"end_date" json:"end_date"`
+}
+
+// NewSchedule validates the schedule given by the user, on is a day of the month or "last"
+// for monthly schedules and a weekday like mon for weekly ones
+func NewSchedule(every string, interval int, on string, start string, end string) (Schedule, error) {
+	s := Schedule{Interval: interval, StartDate: start}
+	frequency, ok := frequencies[strings.ToLower(every)]
+	if !ok {
+		return s, errors.New("invalid frequency '" + every + "'. must be one of day, week, month or year")
+	}
+	s.Frequency = frequency
+	if interval < 1 {
+		return s, errors.New("invalid interval. interval must be greater than 0")
+	}
+	startDate, err := time.Parse(ScheduleDateFormat, start)
+	if err != nil {
+		return s, errors.New("invalid start date '" + start + "'. expected YYYY-MM-DD")
+	}
+	if end != "" {
+		endDate, err := time.Parse(ScheduleDateFormat, end)
+		if err != nil {
+			return s, errors.New("invalid end date '" + end + "'. expected YYYY-MM-DD")
+		}
+		if endDate.Before(startDate) {
+			return s, errors.New("the end date is before the start date")
+		}
+		s.EndDate = &end
+	}
+	if on == "" {
+		return s, nil
+	}
+	day, err := parseOnDay(frequency, strings.ToLower(on))
+	if err != nil {
+		return s, err
+	}
+	s.OnDay = &day
+	return s, nil
+}
+
+func parseOnDay(frequency string, on string) (int, error) {
+	switch frequency {
+	case "month":
+		if on == "last" {
+			return -1, nil
+		}
+		day, err := strconv.Atoi(on)
+		if err != nil || day < 1 || day > 31 {
+			return 0, errors.New("invalid day '" + on + "'. monthly schedules run on a day from 1 to 31 or last")
+		}
+		return day, nil
+	case "week":
+		for i, weekday := range weekdays {
+			if strings.HasPrefix(on, weekday) {
+				if i == 0 {
+					return 7, nil
+				}
+				return i, nil
+			}
+		}
+		day, err := strconv.Atoi(on)
+		if err != nil || day < 1 || day > 7 {
+			return 0, errors.New("invalid weekday '" + on + "'. weekly schedules run on mon, tue, wed, thu, fri, sat or sun")
+		}
+		return day, nil
+	}
+	return 0, errors.New("--on only works with weekly and monthly schedules")
+}
+
+// Occurrences returns the dates after the date after (all when empty) up to and including until
+func (s Schedule) Occurrences(after string, until string) []string {
+	var dates []string
+	s.each(func(date string) bool {
+		if date > until {
+			return false
+		}
+		if date > after {
+			dates = append(dates, date)
+		}
+		return true
+	})
+	return dates
+}
+
+// Next returns the first date after the date after, it is empty once the schedule has ended
+func (s Schedule) Next(after string) string {
+	var next string
+	s.each(func(date string) bool {
+		if date > after {
+			next = date
+			return false
+		}
+		return true
+	})
+	return next
+}
+
+// each calls fn with every date of the schedule in order until fn returns false or the
+// schedule ends
+func (s Schedule) each(fn func(date string) bool) {
+	start, err := time.Parse(ScheduleDateFormat, s.StartDate)
+	if err != nil || s.Interval < 1 {
+		return
+	}
+	for n := 0; ; n++ {
+		date := s.occurrence(start, n)
+		if date.Before(start) {
+			continue
+		}
+		formatted := date.Format(ScheduleDateFormat)
+		if s.EndDate != nil && formatted > *s.EndDate {
+			return
+		}
+		if !fn(formatted) {
+			return
+		}
+	}
+}
+
+// occurrence is the n-th date of the schedule, it may fall before start
+func (s Schedule) occurrence(start time.Time, n int) time.Time {
+	switch s.Frequency {
+	case "week":
+		if s.OnDay != nil {
+			start = start.AddDate(0, 0, (*s.OnDay%7-int(start.Weekday())+7)%7)
+		}
+		return start.AddDate(0, 0, 7*n*s.Interval)
+	case "month":
+		day := start.Day()
+		if s.OnDay != nil {
+			day = *s.OnDay
+		}
+		return dayOfMonth(start.Year(), start.Month()+time.Month(n*s.Interval), day)
+	case "year":
+		return dayOfMonth(start.Year()+n*s.Interval, start.Month(), start.Day())
+	default:
+		return start.AddDate(0, 0, n*s.Interval)
+	}
+}
+
+// dayOfMonth clamps day to the length of the month so the 31st becomes the 30th in april,
+// -1 is the last day
+func dayOfMonth(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if day < 1 || day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func (s Schedule) String() string {
+	text := "every " + s.Frequency
+	if s.Interval > 1 {
+		text = fmt.Sprintf("every %d %ss", s.Interval, s.Frequency)
+	}
+	if s.OnDay != nil {
+		switch {
+		case s.Frequency == "week":
+			text += " on " + weekdays[*s.OnDay%7]
+		case *s.OnDay == -1:
+			text += " on the last day"
+		default:
+			text += fmt.Sprintf(" on day %d", *s.OnDay)
+		}
+	}
+	if s.EndDate != nil {
+		text += " until " + *s.EndDate
+	}
+	return text
+}
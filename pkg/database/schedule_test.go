@@ -0,0 +1,73 @@
+package database_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestScheduleOccurrences(t *testing.T) {
+	tests := []struct {
+		every    string
+		interval int
+		on       string
+		start    string
+		end      string
+		after    string
+		until    string
+		want     []string
+	}{
+		{"month", 1, "1", "2026-01-15", "", "", "2026-04-01", []string{"2026-02-01", "2026-03-01", "2026-04-01"}},
+		{"monthly", 1, "31", "2026-01-31", "", "", "2026-04-30", []string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"}},
+		{"month", 1, "last", "2024-01-10", "", "2024-01-31", "2024-03-31", []string{"2024-02-29", "2024-03-31"}},
+		{"month", 3, "", "2026-01-10", "", "", "2026-12-31", []string{"2026-01-10", "2026-04-10", "2026-07-10", "2026-10-10"}},
+		{"week", 2, "fri", "2026-09-01", "2026-10-10", "", "2026-12-31", []string{"2026-09-04", "2026-09-18", "2026-10-02"}},
+		{"week", 1, "", "2026-09-01", "", "2026-09-01", "2026-09-15", []string{"2026-09-08", "2026-09-15"}},
+		{"day", 10, "", "2026-01-01", "", "", "2026-01-31", []string{"2026-01-01", "2026-01-11", "2026-01-21", "2026-01-31"}},
+		{"year", 1, "", "2024-02-29", "", "", "2026-12-31", []string{"2024-02-29", "2025-02-28", "2026-02-28"}},
+		{"month", 1, "1", "2026-05-02", "", "", "2026-05-31", nil},
+	}
+	for _, test := range tests {
+		s, err := database.NewSchedule(test.every, test.interval, test.on, test.start, test.end)
+		if err != nil {
+			t.Errorf("%s %s from %s: %v", test.every, test.on, test.start, err)
+			continue
+		}
+		got := s.Occurrences(test.after, test.until)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: expected %v, got %v", s, test.want, got)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	s, _ := database.NewSchedule("month", 1, "15", "2026-01-01", "2026-03-31")
+	if next := s.Next(""); next != "2026-01-15" {
+		t.Errorf("expected 2026-01-15, got %s", next)
+	}
+	if next := s.Next("2026-02-15"); next != "2026-03-15" {
+		t.Errorf("expected 2026-03-15, got %s", next)
+	}
+	if next := s.Next("2026-03-15"); next != "" {
+		t.Errorf("expected the schedule to have ended, got %s", next)
+	}
+}
+
+func TestNewScheduleRejects(t *testing.T) {
+	invalid := [][]string{
+		{"fortnight", "", "2026-01-01", ""},
+		{"month", "32", "2026-01-01", ""},
+		{"week", "someday", "2026-01-01", ""},
+		{"year", "3", "2026-01-01", ""},
+		{"month", "", "2026-02-01", "2026-01-01"},
+	}
+	for _, args := range invalid {
+		if _, err := database.NewSchedule(args[0], 1, args[1], args[2], args[3]); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+	if _, err := database.NewSchedule("day", 0, "", "2026-01-01", ""); err == nil {
+		t.Error("expected an interval of 0 to be rejected")
+	}
+}
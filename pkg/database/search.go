@@ -0,0 +1,140 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// FTSQuery turns a search like `coffee OR tea -starbucks` into an FTS5 MATCH expression.
+// Words match as prefixes, "quoted phrases" match as written, AND, OR, NOT and parentheses
+// keep their FTS5 meaning and a leading - excludes a word or phrase from its group.
+func FTSQuery(search string) (string, error) {
+	tokens, err := ftsTokens(search)
+	if err != nil {
+		return "", err
+	}
+	p := &ftsParser{tokens: tokens}
+	return p.group(0)
+}
+
+func ftsTokens(search string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(search); {
+		switch c := search[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		default:
+			start := i
+			if c == '-' {
+				i++
+			}
+			if i < len(search) && search[i] == '"' {
+				end := strings.IndexByte(search[i+1:], '"')
+				if end < 0 {
+					return nil, errors.New("invalid search. missing closing quote")
+				}
+				i += end + 2
+				if i < len(search) && search[i] == '*' {
+					i++
+				}
+			} else {
+				for i < len(search) && !strings.ContainsRune(" \t\n\r()", rune(search[i])) {
+					i++
+				}
+			}
+			tokens = append(tokens, search[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+type ftsParser struct {
+	tokens []string
+	pos    int
+}
+
+// group parses tokens up to the closing parenthesis of the group (or the end of the search),
+// excluded terms are moved behind the rest of the group since FTS5 has no unary NOT
+func (p *ftsParser) group(depth int) (string, error) {
+	var include, exclude []string
+	// an operand is needed at the start of the group and after an operator
+	needOperand := true
+	for p.pos < len(p.tokens) {
+		token := p.tokens[p.pos]
+		p.pos++
+		switch {
+		case token == ")":
+			if depth == 0 {
+				return "", errors.New("invalid search. unexpected ')'")
+			}
+			return joinFTSGroup(include, exclude, needOperand)
+		case token == "(":
+			inner, err := p.group(depth + 1)
+			if err != nil {
+				return "", err
+			}
+			include = append(include, "("+inner+")")
+			needOperand = false
+		case token == "AND" || token == "OR" || token == "NOT":
+			if needOperand {
+				return "", errors.New("invalid search. " + token + " needs a word on both sides")
+			}
+			include = append(include, token)
+			needOperand = true
+		case len(token) > 1 && token[0] == '-':
+			if term, ok := ftsTerm(token[1:]); ok {
+				exclude = append(exclude, term)
+			}
+		default:
+			if term, ok := ftsTerm(token); ok {
+				include = append(include, term)
+				needOperand = false
+			}
+		}
+	}
+	if depth > 0 {
+		return "", errors.New("invalid search. missing ')'")
+	}
+	return joinFTSGroup(include, exclude, needOperand)
+}
+
+func joinFTSGroup(include, exclude []string, needOperand bool) (string, error) {
+	if len(include) == 0 {
+		if len(exclude) > 0 {
+			return "", errors.New("invalid search. at least one word must not be excluded")
+		}
+		return "", errors.New("invalid search. nothing to search for")
+	}
+	if needOperand {
+		return "", errors.New("invalid search. " + include[len(include)-1] + " needs a word on both sides")
+	}
+	query := strings.Join(include, " ")
+	if len(exclude) > 0 {
+		query = "(" + query + ") NOT " + strings.Join(exclude, " NOT ")
+	}
+	return query, nil
+}
+
+// ftsTerm quotes a word or phrase so punctuation cannot be read as FTS5 syntax, words
+// become prefix queries. Tokens without letters or digits are dropped.
+func ftsTerm(token string) (string, bool) {
+	prefix := true
+	if strings.HasPrefix(token, `"`) {
+		prefix = strings.HasSuffix(token, "*")
+		token = strings.Trim(strings.TrimSuffix(token, "*"), `"`)
+	} else {
+		token = strings.TrimRight(token, "*")
+	}
+	if strings.IndexFunc(token, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return "", false
+	}
+	term := `"` + strings.ReplaceAll(token, `"`, `""`) + `"`
+	if prefix {
+		term += "*"
+	}
+	return term, true
+}
@@ -0,0 +1,44 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		search string
+		want   string
+	}{
+		{"coffee", `"coffee"*`},
+		{"coffee OR tea -starbucks", `("coffee"* OR "tea"*) NOT "starbucks"*`},
+		{`"blue bottle" -"gift card"`, `("blue bottle") NOT "gift card"`},
+		{`"blue bot"* caf*`, `"blue bot"* "caf"*`},
+		{"(coffee -decaf) OR tea", `(("coffee"*) NOT "decaf"*) OR "tea"*`},
+		{`it's 7-eleven & co.`, `"it's"* "7-eleven"* "co."*`},
+		{`say "hi`, ""},
+		{"-starbucks", ""},
+		{"coffee OR", ""},
+		{"AND tea", ""},
+		{"(coffee", ""},
+		{"coffee)", ""},
+		{"  ", ""},
+	}
+	for _, test := range tests {
+		got, err := database.FTSQuery(test.search)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %s", test.search, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.search, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q: expected %s, got %s", test.search, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// NormalizeTag strips the optional leading # so "#trip" and "trip" are the same tag
+func NormalizeTag(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+// SplitTagFilter separates wanted tags from negated ones ("!work")
+func SplitTagFilter(tags []string) (include []string, exclude []string) {
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, "!") {
+			exclude = append(exclude, NormalizeTag(tag[1:]))
+		} else {
+			include = append(include, NormalizeTag(tag))
+		}
+	}
+	return include, exclude
+}
+
+func addTags(tx *sqlx.Tx, transactionID int64, tags []string) error {
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		_, err := tx.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			"INSERT OR IGNORE INTO transaction_tags (transaction_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			transactionID, tag,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeTags(tx *sqlx.Tx, transactionID int64, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.Exec(
+			"DELETE FROM transaction_tags WHERE transaction_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)",
+			transactionID, NormalizeTag(tag),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillTags loads the tags of every transaction
+func (r *transactionRepository) fillTags(transactions []Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(transactions))
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ID)
+	}
+	query, args, err := sqlx.In(
+		"SELECT tt.transaction_id, t.name FROM transaction_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.transaction_id IN (?) ORDER BY t.name",
+		ids,
+	)
+	if err != nil {
+		return err
+	}
+	var rows []struct {
+		TransactionID int    `db:"transaction_id"`
+		Name          string `db:"name"`
+	}
+	err = r.db.Select(&rows, r.db.Rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	tags := map[int][]string{}
+	for _, row := range rows {
+		tags[row.TransactionID] = append(tags[row.TransactionID], row.Name)
+	}
+	for i := range transactions {
+		transactions[i].Tags = tags[transactions[i].ID]
+	}
+	return nil
+}
+
+// buildTagQuery returns one predicate for the wanted tags and one for the excluded ones
+func buildTagQuery(include []string, exclude []string, matchAll bool) []predicate {
+	tagged := func(tags []string) (string, []interface{}) {
+		args := make([]interface{}, 0, len(tags))
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(tags)), ", ")
+		return "SELECT tt.transaction_id FROM transaction_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.name IN (" + placeholders + ")", args
+	}
+	var predicates []predicate
+	if len(include) > 0 {
+		query, args := tagged(include)
+		if matchAll {
+			query += " GROUP BY tt.transaction_id HAVING COUNT(DISTINCT t.name) = ?"
+			args = append(args, len(include))
+		}
+		predicates = append(predicates, predicate{sql: "id IN (" + query + ")", args: args})
+	}
+	if len(exclude) > 0 {
+		query, args := tagged(exclude)
+		predicates = append(predicates, predicate{sql: "id NOT IN (" + query + ")", args: args})
+	}
+	return predicates
+}
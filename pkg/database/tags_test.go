@@ -0,0 +1,74 @@
+package database_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+// taggedDescriptions lists the sorted descriptions of the transactions matching tags
+func taggedDescriptions(t *testing.T, tags []string, mode string) []string {
+	t.Helper()
+	transactions, err := database.NewTransactionRepository().GetTransactionsWithConfig(database.TransactionConfig{
+		Page: 1, Limit: 1, All: true, Tags: tags, TagMode: mode,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	descriptions := []string{}
+	for _, transaction := range transactions {
+		descriptions = append(descriptions, transaction.Description)
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
+
+func TestTags(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	repo := database.NewTransactionRepository()
+	_, err := repo.CreateTransactions([]database.Transaction{
+		{Type: "expense", Description: "coffee", Amount: money.Money(350), Tags: []string{"#morning", "work"}},
+		{Type: "expense", Description: "lunch", Amount: money.Money(1200), Tags: []string{"work", "trip"}},
+		{Type: "expense", Description: "dinner", Amount: money.Money(3000), Tags: []string{"trip"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// acc edit --tag trip --tag '!work'
+	add, remove := database.SplitTagFilter([]string{"trip", "!work"})
+	if err := repo.UpdateTransaction(database.UpdateConfig{ID: 1, AddTags: add, RemoveTags: remove}); err != nil {
+		t.Fatal(err)
+	}
+	transactions, err := repo.GetTransactionsWithConfig(database.TransactionConfig{Page: 1, Limit: 1, All: true, Sort: "amt", SortAsc: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coffee := transactions[0]; !reflect.DeepEqual(coffee.Tags, []string{"morning", "trip"}) {
+		t.Error("expected work to be replaced by trip and # to be dropped, got", coffee.Tags)
+	}
+
+	for _, c := range []struct {
+		tags []string
+		mode string
+		want string
+	}{
+		{[]string{"morning", "work"}, "any", "coffee lunch"},
+		{[]string{"morning", "work"}, "", "coffee lunch"},
+		{[]string{"trip", "work"}, "all", "lunch"},
+		{[]string{"!work"}, "any", "coffee dinner"},
+		{[]string{"trip", "!morning"}, "any", "dinner lunch"},
+		{[]string{"#trip", "!morning", "!work"}, "all", "dinner"},
+	} {
+		if got := strings.Join(taggedDescriptions(t, c.tags, c.mode), " "); got != c.want {
+			t.Errorf("tags %v (%s): expected %s, got %s", c.tags, c.mode, c.want, got)
+		}
+	}
+}
@@ -1,22 +1,48 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/elliot40404/acc/pkg/money"
 	"github.com/jmoiron/sqlx"
 )
 
+// ErrNotFound is wrapped by the errors of lookups and updates of transactions that do not
+// exist or are in the trash
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalid is wrapped by the errors of updates that would leave a transaction invalid
+var ErrInvalid = errors.New("invalid transaction")
+
 type transactionRepository struct {
 	db *sqlx.DB
 }
 
 type Transaction struct {
-	ID          int     `db:"id" json:"id"`
-	Type        string  `db:"type" json:"type"`
-	Description string  `db:"description" json:"description"`
-	Amount      float64 `db:"amount" json:"amount"`
-	CreatedAt   string  `db:"created_at" json:"created_at"`
-	UpdatedAt   string  `db:"updated_at" json:"updated_at"`
+	ID          int          `db:"id" json:"id"`
+	Type        string       `db:"type" json:"type"`
+	Description string       `db:"description" json:"description"`
+	Note        string       `db:"note" json:"note,omitempty"`
+	Amount      money.Money  `db:"amount" json:"amount"`
+	Currency    string       `db:"currency" json:"currency"`
+	BaseAmount  *money.Money `db:"-" json:"base_amount"`
+	OccurredAt  string       `db:"occurred_at" json:"occurred_at"`
+	CategoryID  *int         `db:"category_id" json:"category_id"`
+	Category    string       `db:"-" json:"category,omitempty"`
+	AccountID   int          `db:"account_id" json:"account_id"`
+	Account     string       `db:"-" json:"account"`
+	ToAccountID *int         `db:"to_account_id" json:"to_account_id"`
+	ToAccount   string       `db:"-" json:"to_account,omitempty"`
+	Tags        []string     `db:"-" json:"tags"`
+	ExternalID  *string      `db:"external_id" json:"external_id,omitempty"`
+	RecurringID *int         `db:"recurring_id" json:"recurring_id,omitempty"`
+	CreatedAt   string       `db:"created_at" json:"created_at"`
+	UpdatedAt   string       `db:"updated_at" json:"updated_at"`
+	// DeletedAt is set while the transaction is in the trash
+	DeletedAt *string `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
 type TransactionConfig struct {
@@ -31,10 +57,39 @@ type TransactionConfig struct {
 	Sort    string
 	SortAsc bool
 	Desc    string
-	Columns []string
-	IsHRTime bool
-	Format string
-	IsPretty bool
+	// Search is a full-text query over descriptions and notes, see FTSQuery
+	Search string
+	// Category is the path given by the user, CategoryID its resolved id
+	Category   string
+	CategoryID int
+	// Tags are matched with any-of (or all-of when TagMode is "all"), "!tag" excludes
+	Tags    []string
+	TagMode string
+	// Account is the name given by the user, AccountID its resolved id
+	Account   string
+	AccountID int
+	Currency  string
+	// transactions in the trash are left out unless IncludeDeleted is set, Deleted only
+	// matches those
+	IncludeDeleted bool
+	Deleted        bool
+	Columns        []string
+	IsHRTime       bool
+	Format         string
+	IsPretty       bool
+	Journal        JournalConfig
+}
+
+// JournalConfig names the accounts used by the ledger, hledger and beancount formats
+type JournalConfig struct {
+	Assets string
+	// Liabilities (credit cards, loans) are read like Assets on import
+	Liabilities string
+	Income      string
+	Expenses    string
+	// Accounts overrides the journal account of an acc account or category path,
+	// subcategories of a mapped category are appended to it
+	Accounts map[string]string
 }
 
 type DeleteConfig struct {
@@ -44,17 +99,54 @@ type DeleteConfig struct {
 	Ids     []string
 }
 
+// UpdateConfig describes a partial update, nil fields are left untouched
+type UpdateConfig struct {
+	Dry         bool
+	Verbose     bool
+	ID          int
+	Type        *string
+	Description *string
+	Note        *string
+	Amount      *money.Money
+	Currency    *string
+	Date        *string
+	CategoryID  *int
+	AccountID   *int
+	ToAccountID *int
+	AddTags     []string
+	RemoveTags  []string
+}
+
 type TQuery struct {
-	Query   string
-	Config  TransactionConfig
-	isCount bool
+	Query      string
+	Config     TransactionConfig
+	isCount    bool
+	predicates []predicate
+	// match is the translated Search, relevance sorting ranks by it
+	match string
+	// ORDER BY, LIMIT and OFFSET come after the WHERE clause
+	suffix     string
+	suffixArgs []interface{}
 }
 
 type TransactionRepository interface {
-	CreateTransaction(transaction Transaction) error
+	// CreateTransaction returns the id of the new transaction, 0 when it was skipped as
+	// imported or posted before
+	CreateTransaction(transaction Transaction) (int, error)
+	CreateTransactions(transactions []Transaction) (int, error)
+	// GetTransaction returns the stored columns of a transaction, wrapping ErrNotFound when
+	// it does not exist or is in the trash
+	GetTransaction(id int) (Transaction, error)
 	GetTransactionsWithConfig(c TransactionConfig) ([]Transaction, error)
 	GetTransactionCountWithConfig(c TransactionConfig) (int, error)
-	DeleteTransactions(c DeleteConfig) error
+	// DeleteTransactions moves transactions to the trash and returns how many were moved
+	DeleteTransactions(c DeleteConfig) (int, error)
+	// RestoreTransactions takes transactions out of the trash, all of them when ids is empty
+	RestoreTransactions(ids []string) (int, error)
+	// EmptyTrash removes the transactions moved to the trash before the timestamp before for
+	// good, all of them when it is empty
+	EmptyTrash(before string) (int, error)
+	UpdateTransaction(c UpdateConfig) error
 }
 
 func NewTransactionRepository() TransactionRepository {
@@ -65,95 +157,429 @@ func NewTransactionRepository() TransactionRepository {
 	return &transactionRepository{db: db}
 }
 
-func (r *transactionRepository) CreateTransaction(transaction Transaction) error {
-	_, err := r.db.Exec("INSERT INTO transactions (type, description, amount) VALUES (?, ?, ?)", transaction.Type, transaction.Description, transaction.Amount)
-	if err != nil {
+func (r *transactionRepository) CreateTransaction(transaction Transaction) (int, error) {
+	var id int64
+	err := journal(r.db, fmt.Sprintf("add transaction %q", transaction.Description), func(tx *sqlx.Tx) error {
+		var err error
+		id, err = insertTransaction(tx, transaction)
 		return err
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nil
+	return int(id), nil
+}
+
+// CreateTransactions inserts all transactions in a single sql transaction, nothing is stored
+// when one of them fails. Categories given by path only are created as needed and
+// transactions whose ExternalID was already imported into the account are skipped.
+// It returns the number of inserted transactions.
+func (r *transactionRepository) CreateTransactions(transactions []Transaction) (int, error) {
+	description := fmt.Sprintf("add %d transactions", len(transactions))
+	if len(transactions) == 1 {
+		description = fmt.Sprintf("add transaction %q", transactions[0].Description)
+	}
+	inserted := 0
+	err := journal(r.db, description, func(tx *sqlx.Tx) error {
+		for i, transaction := range transactions {
+			id, err := insertTransaction(tx, transaction)
+			if err != nil {
+				if len(transactions) > 1 {
+					return fmt.Errorf("transaction %d (%s): %w", i+1, transaction.Description, err)
+				}
+				return err
+			}
+			if id != 0 {
+				inserted++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+// insertTransaction returns the id of the new transaction, 0 when the transaction was
+// imported or posted before, even when it has been moved to the trash since
+func insertTransaction(tx *sqlx.Tx, transaction Transaction) (int64, error) {
+	accountID := transaction.AccountID
+	if accountID == 0 {
+		accountID = DefaultAccountID
+	}
+	if transaction.RecurringID != nil {
+		var count int
+		err := tx.Get(&count, "SELECT COUNT(*) FROM transactions WHERE recurring_id = ? AND occurred_at = ?", *transaction.RecurringID, transaction.OccurredAt)
+		if err != nil {
+			return 0, err
+		}
+		if count > 0 {
+			return 0, nil
+		}
+	}
+	if transaction.ExternalID != nil {
+		var count int
+		err := tx.Get(&count, "SELECT COUNT(*) FROM transactions WHERE account_id = ? AND external_id = ?", accountID, *transaction.ExternalID)
+		if err != nil {
+			return 0, err
+		}
+		if count > 0 {
+			return 0, nil
+		}
+	}
+	var occurredAt interface{}
+	if transaction.OccurredAt != "" {
+		occurredAt = transaction.OccurredAt
+	}
+	if transaction.CategoryID == nil && transaction.Category != "" {
+		id, err := createCategory(tx, transaction.Category)
+		if err != nil {
+			return 0, err
+		}
+		transaction.CategoryID = &id
+	}
+	var currency interface{}
+	if transaction.Currency != "" {
+		currency = transaction.Currency
+	}
+	res, err := tx.Exec(
+		`INSERT INTO transactions (type, description, note, amount, occurred_at, category_id, account_id, to_account_id, currency, external_id, recurring_id)
+		VALUES (?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), ?, ?, ?, COALESCE(?, (SELECT value FROM settings WHERE key = 'base_currency'), ?), ?, ?)`,
+		transaction.Type, transaction.Description, transaction.Note, transaction.Amount, occurredAt, transaction.CategoryID, accountID, transaction.ToAccountID,
+		currency, DefaultBaseCurrency, transaction.ExternalID, transaction.RecurringID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := addTags(tx, id, transaction.Tags); err != nil {
+		return 0, err
+	}
+	return id, nil
 }
 
 func (r *transactionRepository) GetTransactionsWithConfig(c TransactionConfig) ([]Transaction, error) {
 	var transactions []Transaction
-	query, err := buildQuery(c, false)
+	query, args, err := buildQuery(c, false)
 	if err != nil {
 		return nil, err
 	}
 	if c.Verbose {
-		fmt.Println("SELECT =>", query)
+		fmt.Println("SELECT =>", query, args)
 	}
 	if c.Dry {
 		return nil, nil
 	}
-	err = r.db.Select(&transactions, query)
+	err = r.db.Select(&transactions, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	err = r.fillCategories(transactions)
+	if err != nil {
+		return nil, err
+	}
+	err = r.fillTags(transactions)
+	if err != nil {
+		return nil, err
+	}
+	err = r.fillAccounts(transactions)
+	if err != nil {
+		return nil, err
+	}
+	err = r.fillBaseAmounts(transactions)
 	if err != nil {
 		return nil, err
 	}
 	return transactions, nil
 }
 
+// fillCategories resolves the category path of every transaction
+func (r *transactionRepository) fillCategories(transactions []Transaction) error {
+	var categories []Category
+	err := r.db.Select(&categories, "SELECT id, name, parent_id FROM categories")
+	if err != nil {
+		return err
+	}
+	paths := CategoryPaths(categories)
+	for i := range transactions {
+		if transactions[i].CategoryID != nil {
+			transactions[i].Category = paths[*transactions[i].CategoryID]
+		}
+	}
+	return nil
+}
+
+// fillAccounts resolves the account names of every transaction
+func (r *transactionRepository) fillAccounts(transactions []Transaction) error {
+	names, err := accountNames(r.db)
+	if err != nil {
+		return err
+	}
+	for i := range transactions {
+		transactions[i].Account = names[transactions[i].AccountID]
+		if transactions[i].ToAccountID != nil {
+			transactions[i].ToAccount = names[*transactions[i].ToAccountID]
+		}
+	}
+	return nil
+}
+
+// fillBaseAmounts converts every amount to the base currency using the rate effective on
+// the transaction date, BaseAmount stays nil when no rate is known
+func (r *transactionRepository) fillBaseAmounts(transactions []Transaction) error {
+	base, err := baseCurrency(r.db)
+	if err != nil {
+		return err
+	}
+	var rates []FxRate
+	err = r.db.Select(&rates, "SELECT from_currency, to_currency, rate, effective_date FROM fx_rates")
+	if err != nil {
+		return err
+	}
+	table := NewRateTable(rates)
+	for i := range transactions {
+		date := transactions[i].OccurredAt
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		converted, ok := table.Convert(transactions[i].Amount, transactions[i].Currency, base, date)
+		if ok {
+			transactions[i].BaseAmount = &converted
+		}
+	}
+	return nil
+}
+
 func (r *transactionRepository) GetTransactionCountWithConfig(c TransactionConfig) (int, error) {
-	query, err := buildQuery(c, true)
+	query, args, err := buildQuery(c, true)
 	if err != nil {
 		return 0, err
 	}
 	if c.Verbose {
-		fmt.Println("COUNT =>", query)
+		fmt.Println("COUNT =>", query, args)
 	}
 	if c.Dry {
 		return 0, nil
 	}
 	var count int
-	err = r.db.Get(&count, query)
+	err = r.db.Get(&count, query, args...)
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
-func (r *transactionRepository) DeleteTransactions(c DeleteConfig) error {
-	var query string
-	if c.All {
-		query = "DELETE FROM transactions"
-		if c.Verbose {
-			fmt.Println("DELETE =>", query)
+func (r *transactionRepository) DeleteTransactions(c DeleteConfig) (int, error) {
+	query := "UPDATE transactions SET deleted_at = CURRENT_TIMESTAMP WHERE deleted_at IS NULL"
+	description := "move all transactions to the trash"
+	var args []interface{}
+	if !c.All {
+		var err error
+		query, args, err = sqlx.In(query+" AND id IN (?)", c.Ids)
+		if err != nil {
+			return 0, err
 		}
-		if c.Dry {
-			return nil
+		query = r.db.Rebind(query)
+		description = "move transactions " + strings.Join(c.Ids, ", ") + " to the trash"
+	}
+	if c.Verbose {
+		fmt.Println("DELETE =>", query, args)
+	}
+	if c.Dry {
+		return 0, nil
+	}
+	var deleted int64
+	err := journal(r.db, description, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
 		}
-		_, err := r.db.Exec(query)
+		deleted, err = res.RowsAffected()
+		return err
+	})
+	return int(deleted), err
+}
+
+func (r *transactionRepository) RestoreTransactions(ids []string) (int, error) {
+	query := "UPDATE transactions SET deleted_at = NULL WHERE deleted_at IS NOT NULL"
+	description := "restore all transactions from the trash"
+	var args []interface{}
+	if len(ids) > 0 {
+		var err error
+		query, args, err = sqlx.In(query+" AND id IN (?)", ids)
+		if err != nil {
+			return 0, err
+		}
+		query = r.db.Rebind(query)
+		description = "restore transactions " + strings.Join(ids, ", ") + " from the trash"
+	}
+	var restored int64
+	err := journal(r.db, description, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(query, args...)
 		if err != nil {
 			return err
 		}
-		_, err = r.db.Exec("DELETE FROM sqlite_sequence WHERE name='transactions'")
+		restored, err = res.RowsAffected()
+		return err
+	})
+	return int(restored), err
+}
+
+func (r *transactionRepository) EmptyTrash(before string) (int, error) {
+	where := "deleted_at IS NOT NULL"
+	description := "empty the trash"
+	var args []interface{}
+	if before != "" {
+		where += " AND deleted_at < ?"
+		args = append(args, before)
+		description = "empty the trash up to " + before
+	}
+	var removed int64
+	err := journal(r.db, description, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("DELETE FROM transaction_tags WHERE transaction_id IN (SELECT id FROM transactions WHERE "+where+")", args...)
 		if err != nil {
 			return err
 		}
-		// VACUUM is used to rebuild the database file, repacking it into a minimal amount of disk space.
-		_, err = r.db.Exec("VACUUM")
+		res, err := tx.Exec("DELETE FROM transactions WHERE "+where, args...)
 		if err != nil {
 			return err
 		}
+		removed, err = res.RowsAffected()
+		return err
+	})
+	return int(removed), err
+}
+
+func (r *transactionRepository) GetTransaction(id int) (Transaction, error) {
+	var transaction Transaction
+	err := r.db.Get(&transaction, "SELECT * FROM transactions WHERE id = ? AND deleted_at IS NULL", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return transaction, fmt.Errorf("transaction %d %w", id, ErrNotFound)
+	}
+	return transaction, err
+}
+
+// checkTransfer validates the type and accounts the transaction ends up with, the check
+// constraint of the table would only say that it failed
+func (r *transactionRepository) checkTransfer(c UpdateConfig) error {
+	if c.Type == nil && c.AccountID == nil && c.ToAccountID == nil {
 		return nil
 	}
-	q, args, err := sqlx.In("DELETE FROM transactions WHERE id IN (?)", c.Ids)
+	current, err := r.GetTransaction(c.ID)
 	if err != nil {
 		return err
 	}
-	q = r.db.Rebind(q)
-	if c.Verbose {
-		fmt.Println("DELETE =>", q, args)
+	transactionType := current.Type
+	if c.Type != nil {
+		transactionType = *c.Type
 	}
-	if c.Dry {
+	if transactionType != "transfer" {
+		if c.ToAccountID != nil {
+			return fmt.Errorf("%w: only transfers have a destination account", ErrInvalid)
+		}
 		return nil
 	}
-	_, err = r.db.Exec(q, args...)
-	if err != nil {
-		return err
+	accountID, toAccountID := current.AccountID, current.ToAccountID
+	if c.AccountID != nil {
+		accountID = *c.AccountID
+	}
+	if c.ToAccountID != nil {
+		toAccountID = c.ToAccountID
+	}
+	if toAccountID == nil {
+		return fmt.Errorf("%w: transfers need a destination account", ErrInvalid)
+	}
+	if *toAccountID == accountID {
+		return fmt.Errorf("%w: cannot transfer to the same account", ErrInvalid)
 	}
 	return nil
 }
 
+func (r *transactionRepository) UpdateTransaction(c UpdateConfig) error {
+	var sets []string
+	var args []interface{}
+	if c.Type != nil {
+		sets = append(sets, "type = ?")
+		args = append(args, *c.Type)
+		// only transfers have a destination account
+		if *c.Type != "transfer" {
+			sets = append(sets, "to_account_id = NULL")
+		}
+	}
+	if c.Description != nil {
+		sets = append(sets, "description = ?")
+		args = append(args, *c.Description)
+	}
+	if c.Note != nil {
+		sets = append(sets, "note = ?")
+		args = append(args, *c.Note)
+	}
+	if c.Amount != nil {
+		sets = append(sets, "amount = ?")
+		args = append(args, *c.Amount)
+	}
+	if c.Currency != nil {
+		sets = append(sets, "currency = ?")
+		args = append(args, *c.Currency)
+	}
+	if c.Date != nil {
+		sets = append(sets, "occurred_at = ?")
+		args = append(args, *c.Date)
+	}
+	if c.CategoryID != nil {
+		sets = append(sets, "category_id = ?")
+		args = append(args, *c.CategoryID)
+	}
+	if c.AccountID != nil {
+		sets = append(sets, "account_id = ?")
+		args = append(args, *c.AccountID)
+	}
+	if c.ToAccountID != nil {
+		sets = append(sets, "to_account_id = ?")
+		args = append(args, *c.ToAccountID)
+	}
+	if len(sets) == 0 && len(c.AddTags) == 0 && len(c.RemoveTags) == 0 {
+		return errors.New("nothing to update")
+	}
+	if err := r.checkTransfer(c); err != nil {
+		return err
+	}
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, c.ID)
+	query := "UPDATE transactions SET " + strings.Join(sets, ", ") + " WHERE id = ? AND deleted_at IS NULL"
+	if c.Verbose {
+		fmt.Println("UPDATE =>", query, args)
+		if len(c.AddTags) > 0 || len(c.RemoveTags) > 0 {
+			fmt.Println("TAGS =>", "add", c.AddTags, "remove", c.RemoveTags)
+		}
+	}
+	if c.Dry {
+		return nil
+	}
+	return journal(r.db, fmt.Sprintf("edit transaction %d", c.ID), func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return fmt.Errorf("transaction %d %w", c.ID, ErrNotFound)
+		}
+		err = addTags(tx, int64(c.ID), c.AddTags)
+		if err != nil {
+			return err
+		}
+		return removeTags(tx, int64(c.ID), c.RemoveTags)
+	})
+}
+
 func NewQuery(t TransactionConfig, isCount bool) TQuery {
 	if isCount {
 		return TQuery{
@@ -169,21 +595,28 @@ func NewQuery(t TransactionConfig, isCount bool) TQuery {
 	}
 }
 
-func buildQuery(c TransactionConfig, isCount bool) (string, error) {
+func buildQuery(c TransactionConfig, isCount bool) (string, []interface{}, error) {
 	q := NewQuery(c, isCount)
 	// q.AddColumns()
 	q.AddType()
 	q.AddDate()
 	q.AddAmount()
 	q.AddDesc()
-	if isCount {
-		return q.Build(), nil
+	if err := q.AddSearch(); err != nil {
+		return "", nil, err
 	}
-	q.AddSort()
-	if c.All {
-		return q.Build(), nil
+	q.AddCategory()
+	q.AddTags()
+	q.AddAccount()
+	q.AddCurrency()
+	q.AddDeleted()
+	if !isCount {
+		q.AddSort()
+		if !c.All {
+			q.AddLimit()
+			q.AddOffset()
+		}
 	}
-	q.AddLimit()
-	q.AddOffset()
-	return q.Build(), nil
+	query, args := q.Build()
+	return query, args, nil
 }
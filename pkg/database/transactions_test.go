@@ -0,0 +1,163 @@
+package database_test
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/database"
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+func TestTrash(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	repo := database.NewTransactionRepository()
+	_, err := repo.CreateTransactions([]database.Transaction{
+		{Type: "expense", Description: "coffee", Amount: money.Money(350)},
+		{Type: "expense", Description: "lunch", Amount: money.Money(1200)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.DeleteTransactions(database.DeleteConfig{Ids: []string{"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	all := database.TransactionConfig{Page: 1, Limit: 1, All: true}
+	if n, _ := repo.GetTransactionCountWithConfig(all); n != 1 {
+		t.Error("expected 1 transaction outside of the trash, got", n)
+	}
+	if _, err := repo.GetTransaction(1); !errors.Is(err, database.ErrNotFound) {
+		t.Error("expected a transaction in the trash not to be found, got", err)
+	}
+	if lunch, err := repo.GetTransaction(2); err != nil || lunch.Description != "lunch" {
+		t.Error("expected lunch, got", lunch, err)
+	}
+	trash := all
+	trash.Deleted = true
+	trashed, err := repo.GetTransactionsWithConfig(trash)
+	if err != nil || len(trashed) != 1 || trashed[0].Description != "coffee" || trashed[0].DeletedAt == nil {
+		t.Fatal("expected coffee in the trash, got", trashed, err)
+	}
+	all.IncludeDeleted = true
+	if n, _ := repo.GetTransactionCountWithConfig(all); n != 2 {
+		t.Error("expected 2 transactions including the trash, got", n)
+	}
+	amount := money.Money(400)
+	if err := repo.UpdateTransaction(database.UpdateConfig{ID: 1, Amount: &amount}); err == nil {
+		t.Error("expected transactions in the trash to be read only")
+	}
+	if n, err := repo.RestoreTransactions([]string{"1"}); err != nil || n != 1 {
+		t.Error("expected 1 restored transaction, got", n, err)
+	}
+	repo.DeleteTransactions(database.DeleteConfig{All: true})
+	// nothing was moved to the trash before 2000
+	if n, err := repo.EmptyTrash("2000-01-01 00:00:00"); err != nil || n != 0 {
+		t.Error("expected nothing removed, got", n, err)
+	}
+	if n, err := repo.EmptyTrash(""); err != nil || n != 2 {
+		t.Error("expected 2 removed transactions, got", n, err)
+	}
+	if n, _ := repo.GetTransactionCountWithConfig(all); n != 0 {
+		t.Error("expected no transactions left, got", n)
+	}
+}
+
+func TestUpdateTransfer(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	accounts := database.NewAccountRepository()
+	if err := accounts.CreateAccount("savings"); err != nil {
+		t.Fatal(err)
+	}
+	savings, err := accounts.FindAccount("savings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := database.NewTransactionRepository()
+	if _, err := repo.CreateTransaction(database.Transaction{Type: "expense", Description: "rent", Amount: money.Money(90000)}); err != nil {
+		t.Fatal(err)
+	}
+	transfer, expense, defaultID := "transfer", "expense", database.DefaultAccountID
+	for _, c := range []database.UpdateConfig{
+		{ID: 1, Type: &transfer},
+		{ID: 1, ToAccountID: &savings},
+		{ID: 1, Type: &transfer, ToAccountID: &defaultID},
+		{ID: 1, Type: &transfer, ToAccountID: &savings, Dry: true, AccountID: &savings},
+	} {
+		if err := repo.UpdateTransaction(c); !errors.Is(err, database.ErrInvalid) {
+			t.Error("expected an invalid transaction, got", err)
+		}
+	}
+	if err := repo.UpdateTransaction(database.UpdateConfig{ID: 9, Type: &transfer}); !errors.Is(err, database.ErrNotFound) {
+		t.Error("expected transaction 9 not to be found, got", err)
+	}
+	if err := repo.UpdateTransaction(database.UpdateConfig{ID: 1, Type: &transfer, ToAccountID: &savings}); err != nil {
+		t.Fatal(err)
+	}
+	// changing the type back drops the destination
+	if err := repo.UpdateTransaction(database.UpdateConfig{ID: 1, Type: &expense}); err != nil {
+		t.Fatal(err)
+	}
+	if rent, _ := repo.GetTransaction(1); rent.Type != "expense" || rent.ToAccountID != nil {
+		t.Error("expected an expense without a destination, got", rent)
+	}
+}
+
+func TestUpdateTransaction(t *testing.T) {
+	database.DBPATH = filepath.Join(t.TempDir(), "acc.db")
+	if err := database.InitApplication(); err != nil {
+		t.Fatal(err)
+	}
+	repo := database.NewTransactionRepository()
+	_, err := repo.CreateTransaction(database.Transaction{
+		Type: "expense", Description: "coffee", Note: "with milk", Amount: money.Money(350), OccurredAt: "2024-01-31 08:00:00", Category: "Food",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.GetDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CURRENT_TIMESTAMP has a resolution of seconds
+	if _, err := db.Exec("UPDATE transactions SET updated_at = '2024-01-31 08:00:00'"); err != nil {
+		t.Fatal(err)
+	}
+	before, err := repo.GetTransaction(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amount := money.Money(400)
+	if err := repo.UpdateTransaction(database.UpdateConfig{ID: 1, Amount: &amount, Dry: true}); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := repo.GetTransaction(1); !reflect.DeepEqual(after, before) {
+		t.Error("expected a dry run to leave the transaction unchanged, got", after)
+	}
+
+	if err := repo.UpdateTransaction(database.UpdateConfig{ID: 1, Amount: &amount}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := repo.GetTransaction(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Amount != 400 {
+		t.Error("expected the amount to be updated, got", after.Amount)
+	}
+	if after.UpdatedAt == before.UpdatedAt {
+		t.Error("expected updated_at to be bumped, got", after.UpdatedAt)
+	}
+	// only the given fields change
+	after.Amount, after.UpdatedAt = before.Amount, before.UpdatedAt
+	if !reflect.DeepEqual(after, before) {
+		t.Errorf("expected only the amount to change, got\n%+v\nbefore\n%+v", after, before)
+	}
+}
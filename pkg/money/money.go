@@ -0,0 +1,149 @@
+// Package money represents amounts as integer minor units so sums and comparisons are exact
+package money
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// number of minor units digits used when no currency specific value applies (cents)
+const DefaultDecimals = 2
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Money is an amount in minor units, e.g. 1234 is 12.34 with 2 decimals
+type Money int64
+
+// Parse reads a decimal amount like "12.34", "-5" or "0.1" using DefaultDecimals
+func Parse(s string) (Money, error) {
+	return ParseWithDecimals(s, DefaultDecimals)
+}
+
+// ParseWithDecimals reads a decimal amount, more fractional digits than decimals is an error
+func ParseWithDecimals(s string, decimals int) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	if hasFraction && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	if len(fraction) > decimals {
+		// allow trailing zeros beyond the precision, 1.500 is still exact
+		if strings.Trim(fraction[decimals:], "0") != "" {
+			return 0, ErrInvalidAmount
+		}
+		fraction = fraction[:decimals]
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction))
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+	n, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		n = -n
+	}
+	return Money(n), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount with DefaultDecimals, e.g. 12.34
+func (m Money) String() string {
+	return m.Format(DefaultDecimals)
+}
+
+// Format renders the amount with the given number of decimals
+func (m Money) Format(decimals int) string {
+	n := int64(m)
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+	digits := strconv.FormatInt(n, 10)
+	if decimals <= 0 {
+		return sign + digits
+	}
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	point := len(digits) - decimals
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Div divides by n rounding half away from zero, used for averages
+func (m Money) Div(n int64) Money {
+	if n == 0 {
+		return 0
+	}
+	q, r := int64(m)/n, int64(m)%n
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= abs(n) {
+		if (int64(m) < 0) != (n < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Money(q)
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	parsed, err := Parse(strings.Trim(string(b), `"`))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Mul multiplies by an exact rate rounding half away from zero, used for currency conversion
+func (m Money) Mul(rate *big.Rat) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), rate)
+	num, denom := product.Num(), product.Denom()
+	q, r := new(big.Int).QuoRem(num, denom, new(big.Int))
+	// |2r| >= denom means the fractional part is at least one half
+	if r.Abs(r).Lsh(r, 1).Cmp(denom) >= 0 {
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Money(q.Int64())
+}
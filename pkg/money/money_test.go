@@ -0,0 +1,97 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/elliot40404/acc/pkg/money"
+)
+
+func TestParse(t *testing.T) {
+	valid := map[string]money.Money{
+		"12.34":  1234,
+		"0.1":    10,
+		".5":     50,
+		"-5":     -500,
+		"+3.2":   320,
+		"1.500":  150,
+		"100":    10000,
+		" 7.05 ": 705,
+	}
+	for input, expected := range valid {
+		r, err := money.Parse(input)
+		if err != nil || r != expected {
+			t.Errorf("%q: expected %d, got %d (%v)", input, expected, r, err)
+		}
+	}
+	for _, input := range []string{"", "-", "1.", "1.234", "abc", "1e3", "1,5", "1.2.3"} {
+		if _, err := money.Parse(input); err == nil {
+			t.Errorf("%q: expected error", input)
+		}
+	}
+	r, err := money.ParseWithDecimals("1200", 0)
+	if err != nil || r != 1200 {
+		t.Error("expected 1200, got", r, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := map[money.Money]string{
+		1234: "12.34",
+		5:    "0.05",
+		-50:  "-0.50",
+		0:    "0.00",
+	}
+	for input, expected := range cases {
+		if r := input.String(); r != expected {
+			t.Errorf("%d: expected %s, got %s", input, expected, r)
+		}
+	}
+	if r := money.Money(1234).Format(0); r != "1234" {
+		t.Error("expected 1234, got", r)
+	}
+	if r := money.Money(5).Format(3); r != "0.005" {
+		t.Error("expected 0.005, got", r)
+	}
+}
+
+func TestDiv(t *testing.T) {
+	if r := money.Money(1000).Div(3); r != 333 {
+		t.Error("expected 333, got", r)
+	}
+	if r := money.Money(1001).Div(2); r != 501 {
+		t.Error("expected 501, got", r)
+	}
+	if r := money.Money(-1001).Div(2); r != -501 {
+		t.Error("expected -501, got", r)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b, _ := json.Marshal(struct {
+		Amount money.Money `json:"amount"`
+	}{1234})
+	if string(b) != `{"amount":12.34}` {
+		t.Error("expected {\"amount\":12.34}, got", string(b))
+	}
+	var m money.Money
+	if err := json.Unmarshal([]byte("0.3"), &m); err != nil || m != 30 {
+		t.Error("expected 30, got", m, err)
+	}
+}
+
+func TestMul(t *testing.T) {
+	if r := money.Money(1000).Mul(big.NewRat(108, 100)); r != 1080 {
+		t.Error("expected 1080, got", r)
+	}
+	if r := money.Money(1).Mul(big.NewRat(1, 2)); r != 1 {
+		t.Error("expected 1, got", r)
+	}
+	if r := money.Money(-1).Mul(big.NewRat(1, 2)); r != -1 {
+		t.Error("expected -1, got", r)
+	}
+	if r := money.Money(1000).Mul(big.NewRat(1, 3)); r != 333 {
+		t.Error("expected 333, got", r)
+	}
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// first day of the week and month the fiscal year starts in, see acc config
+var (
+	WeekStart       = time.Monday
+	FiscalYearStart = time.January
+)
+
+// StartOfWeek is the first day of the week t falls in
+func StartOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -(int(day.Weekday())-int(WeekStart)+7)%7)
+}
+
+// StartOfFiscalYear is the first day of the fiscal year t falls in
+func StartOfFiscalYear(t time.Time) time.Time {
+	year := t.Year()
+	if t.Month() < FiscalYearStart {
+		year--
+	}
+	return time.Date(year, FiscalYearStart, 1, 0, 0, 0, 0, t.Location())
+}
+
+// ParseWeekday reads a day name like monday or mon
+func ParseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return day, nil
+		}
+	}
+	return 0, errors.New("invalid day. expected a day of the week like monday or sunday")
+}
+
+// ParseMonth reads a month name like april or apr, or its number
+func ParseMonth(s string) (time.Month, error) {
+	if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= 12 {
+		return time.Month(n), nil
+	}
+	s = strings.ToLower(s)
+	for month := time.January; month <= time.December; month++ {
+		name := strings.ToLower(month.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return month, nil
+		}
+	}
+	return 0, errors.New("invalid month. expected a month like april, apr or 4")
+}
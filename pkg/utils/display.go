@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"github.com/elliot40404/acc/pkg/money"
+	"github.com/itlightning/dateparse"
+)
+
+// display preferences of tables, see acc config
+var (
+	// DateFormat is the Go layout dates are shown in, e.g. 02.01.2006, they are shown as
+	// stored when it is empty
+	DateFormat string
+	// CurrencySymbol shows amounts with the symbol of their currency, e.g. $12.00
+	CurrencySymbol bool
+)
+
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"CNY": "¥",
+	"INR": "₹",
+	"KRW": "₩",
+	"RUB": "₽",
+	"TRY": "₺",
+	"BRL": "R$",
+	"CAD": "CA$",
+	"AUD": "A$",
+	"NZD": "NZ$",
+	"MXN": "MX$",
+	"ILS": "₪",
+	"VND": "₫",
+	"PHP": "₱",
+	"NGN": "₦",
+	"UAH": "₴",
+}
+
+// FormatAmount renders amount for a table, with the symbol of currency when CurrencySymbol
+// is set. Currencies without a known symbol get their code appended.
+func FormatAmount(amount money.Money, currency string) string {
+	if !CurrencySymbol || currency == "" {
+		return amount.String()
+	}
+	symbol, ok := currencySymbols[currency]
+	if !ok {
+		return amount.String() + " " + currency
+	}
+	if amount < 0 {
+		return "-" + symbol + (-amount).String()
+	}
+	return symbol + amount.String()
+}
+
+// FormatDate renders a stored date with DateFormat
+func FormatDate(date string) string {
+	if DateFormat == "" {
+		return date
+	}
+	t, err := dateparse.ParseAny(date)
+	if err != nil {
+		return date
+	}
+	return t.Format(DateFormat)
+}
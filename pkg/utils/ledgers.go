@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultLedger is the ledger whose database is APPDIR/acc.db, the only one before acc
+// supported several ledgers
+const DefaultLedger = "default"
+
+const LedgerNameError = "invalid ledger name. use lowercase letters, digits and dashes, e.g. business or side-project"
+
+// file in APPDIR holding the ledger picked with acc ledger use
+const currentLedgerFile = "ledger"
+
+var ledgerNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func ValidateLedgerName(name string) error {
+	if !ledgerNamePattern.MatchString(name) {
+		return errors.New(LedgerNameError)
+	}
+	return nil
+}
+
+// LedgerDir is the directory of a ledger's database and backups
+func LedgerDir(name string) string {
+	if name == DefaultLedger {
+		return APPDIR()
+	}
+	return filepath.Join(APPDIR(), "ledgers", name)
+}
+
+func LedgerPath(name string) string {
+	return filepath.Join(LedgerDir(name), "acc.db")
+}
+
+// CurrentLedger is the ledger picked with acc ledger use, the default ledger until then
+func CurrentLedger() string {
+	b, err := os.ReadFile(filepath.Join(APPDIR(), currentLedgerFile))
+	if err != nil {
+		return DefaultLedger
+	}
+	name := strings.TrimSpace(string(b))
+	if ValidateLedgerName(name) != nil {
+		return DefaultLedger
+	}
+	return name
+}
+
+func SetCurrentLedger(name string) error {
+	if err := os.MkdirAll(APPDIR(), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(APPDIR(), currentLedgerFile), []byte(name+"\n"), 0644)
+}
+
+// Ledgers returns the names of the ledgers that have been created, the default ledger first
+func Ledgers() ([]string, error) {
+	var names []string
+	if IsInitialized(LedgerPath(DefaultLedger)) {
+		names = append(names, DefaultLedger)
+	}
+	entries, err := os.ReadDir(filepath.Join(APPDIR(), "ledgers"))
+	if errors.Is(err, os.ErrNotExist) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && ValidateLedgerName(entry.Name()) == nil && IsInitialized(LedgerPath(entry.Name())) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
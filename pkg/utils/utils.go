@@ -6,10 +6,13 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/elliot40404/acc/pkg/money"
 	"github.com/itlightning/dateparse"
 )
 
@@ -18,8 +21,13 @@ const (
 	DateRangeSyntaxError   = "invalid date range synxtax. Must be one of the following: :date, date:, date:date"
 	AmountSyntaxError      = "invalid amount syntax. amount must be in a valid format"
 	AmountRangeSyntaxError = "invalid amount range syntax. must be one of the following: :amount, amount:, amount:amount"
+	SingleDateError        = "invalid date. expected a single date like 2024-01-31, today or yesterday"
+	AgeSyntaxError         = "invalid age. expected a number of days, weeks or hours like 30d, 2w or 12h"
 )
 
+// layout used when writing timestamps to the database (matches CURRENT_TIMESTAMP)
+const DBTimeFormat = "2006-01-02 15:04:05"
+
 var ValidDateBuiltins = []string{
 	"today",
 	"yesterday",
@@ -38,31 +46,44 @@ func PrintError(err error, message string, debug bool) {
 	}
 }
 
-func IsInitialized() bool {
-	homedir, err := os.UserHomeDir()
-	if err != nil {
-		return false
-	}
-	if _, err := os.Stat(homedir + "/.acc/.initialized"); err == nil {
-		return true
-	}
-	return false
+// IsInitialized reports whether the database at dbPath has been created with acc init
+// or acc ledger create
+func IsInitialized(dbPath string) bool {
+	_, err := os.Stat(dbPath)
+	return err == nil
 }
 
+// DBPATH is the database in use: $ACC_DB or the database of the ledger picked with
+// acc ledger use
 func DBPATH() string {
-	homedir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
+	if path := os.Getenv("ACC_DB"); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+		return path
 	}
-	return homedir + "/.acc/acc.db"
+	return LedgerPath(CurrentLedger())
 }
 
+// APPDIR is where acc keeps its data: $ACC_HOME, ~/.acc when it exists, acc in
+// $XDG_DATA_HOME when it is set, or ~/.acc
 func APPDIR() string {
+	if dir := os.Getenv("ACC_HOME"); dir != "" {
+		return dir
+	}
 	homedir, err := os.UserHomeDir()
 	if err != nil {
 		return ""
 	}
-	return homedir + "/.acc"
+	// data written before acc honored XDG_DATA_HOME stays where it is
+	legacy := filepath.Join(homedir, ".acc")
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "acc")
+	}
+	return legacy
 }
 
 // conver time to human readable time
@@ -157,6 +178,46 @@ func IsValidDateFormat(date string) bool {
 	return err == nil
 }
 
+// ParseDate resolves a single date (no ranges or period builtins like thisweek)
+func ParseDate(date string) (time.Time, error) {
+	switch date {
+	case "today":
+		return time.Now().UTC(), nil
+	case "yesterday":
+		return time.Now().UTC().AddDate(0, 0, -1), nil
+	}
+	if IsValueRange(date) {
+		return time.Time{}, errors.New(SingleDateError)
+	}
+	if err := Checkdate(&date); err != nil {
+		return time.Time{}, err
+	}
+	t, err := dateparse.ParseAny(date)
+	if err != nil {
+		return time.Time{}, errors.New(SingleDateError)
+	}
+	return t, nil
+}
+
+// ParseAge reads ages like 30d and 2w on top of the units of time.ParseDuration
+func ParseAge(age string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	if len(age) > 1 {
+		if unit, ok := units[age[len(age)-1:]]; ok {
+			n, err := strconv.Atoi(age[:len(age)-1])
+			if err != nil || n < 0 {
+				return 0, errors.New(AgeSyntaxError)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(age)
+	if err != nil || d < 0 {
+		return 0, errors.New(AgeSyntaxError)
+	}
+	return d, nil
+}
+
 func ConvertToDateFormat(date string) string {
 	t, _ := dateparse.ParseAny(date)
 	// convert to YYYY-MM-DD format
@@ -209,8 +270,7 @@ func CheckAmountRange(amount *string) error {
 }
 
 func IsValidAmountFormat(amount string) bool {
-	// parse amount to float64
-	_, err := strconv.ParseFloat(amount, 64)
+	_, err := money.Parse(amount)
 	return err == nil
 }
 
@@ -243,4 +303,4 @@ func ExecShellCmd(command string, args ...string) string {
 		os.Exit(1)
 	}
 	return string(out)
-}
\ No newline at end of file
+}
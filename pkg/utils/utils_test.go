@@ -1,7 +1,10 @@
 package utils_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/elliot40404/acc/pkg/utils"
 )
@@ -49,4 +52,102 @@ func TestPadDate(t *testing.T) {
 	if r != "2020-01-01" {
 		t.Error("expected 2020-01-01, got", r)
 	}
-}
\ No newline at end of file
+}
+
+func TestParseAge(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for age, want := range cases {
+		if r, err := utils.ParseAge(age); err != nil || r != want {
+			t.Error("expected", want, "for", age, "got", r, err)
+		}
+	}
+	for _, age := range []string{"", "d", "-1d", "30 days", "1y"} {
+		if _, err := utils.ParseAge(age); err == nil {
+			t.Error("expected an error for", age)
+		}
+	}
+}
+
+func TestAPPDIR(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ACC_HOME", "")
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	if r := utils.APPDIR(); r != filepath.Join(home, "data", "acc") {
+		t.Error("expected acc in XDG_DATA_HOME, got", r)
+	}
+	// an existing ~/.acc keeps being used
+	if err := os.Mkdir(filepath.Join(home, ".acc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if r := utils.APPDIR(); r != filepath.Join(home, ".acc") {
+		t.Error("expected ~/.acc, got", r)
+	}
+	t.Setenv("ACC_HOME", filepath.Join(home, "books"))
+	if r := utils.APPDIR(); r != filepath.Join(home, "books") {
+		t.Error("expected ACC_HOME, got", r)
+	}
+}
+
+func TestLedgers(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ACC_HOME", dir)
+	t.Setenv("ACC_DB", "")
+	if r := utils.DBPATH(); r != filepath.Join(dir, "acc.db") {
+		t.Error("expected the default ledger, got", r)
+	}
+	for _, name := range []string{"business", "side-project"} {
+		if err := os.MkdirAll(utils.LedgerDir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(utils.LedgerPath(name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := utils.Ledgers()
+	if err != nil || len(names) != 2 || names[0] != "business" {
+		t.Error("expected business and side-project, got", names, err)
+	}
+	if err := utils.SetCurrentLedger("business"); err != nil {
+		t.Fatal(err)
+	}
+	if r := utils.DBPATH(); r != filepath.Join(dir, "ledgers", "business", "acc.db") {
+		t.Error("expected the business ledger, got", r)
+	}
+	t.Setenv("ACC_DB", filepath.Join(dir, "other.db"))
+	if r := utils.DBPATH(); r != filepath.Join(dir, "other.db") {
+		t.Error("expected ACC_DB, got", r)
+	}
+	for _, name := range []string{"Business", "a b", "-x", "../x", ""} {
+		if utils.ValidateLedgerName(name) == nil {
+			t.Error("expected", name, "to be an invalid ledger name")
+		}
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	defer func() { utils.WeekStart, utils.FiscalYearStart = time.Monday, time.January }()
+	// 2026-03-15 is a sunday
+	sunday := time.Date(2026, 3, 15, 18, 0, 0, 0, time.UTC)
+	if r := utils.StartOfWeek(sunday).Format("2006-01-02"); r != "2026-03-09" {
+		t.Error("expected 2026-03-09, got", r)
+	}
+	utils.WeekStart = time.Sunday
+	if r := utils.StartOfWeek(sunday).Format("2006-01-02"); r != "2026-03-15" {
+		t.Error("expected 2026-03-15, got", r)
+	}
+	utils.FiscalYearStart = time.April
+	if r := utils.StartOfFiscalYear(sunday).Format("2006-01-02"); r != "2025-04-01" {
+		t.Error("expected 2025-04-01, got", r)
+	}
+	if m, err := utils.ParseMonth("apr"); err != nil || m != time.April {
+		t.Error("expected april, got", m, err)
+	}
+	if _, err := utils.ParseWeekday("mo"); err == nil {
+		t.Error("expected mo to be an invalid day")
+	}
+}